@@ -1,39 +1,94 @@
 package octane
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/go-playground/form/v4"
+	"github.com/go-playground/validator/v10"
+	"github.com/josephspurrier/octane/internal/validation"
 	"github.com/labstack/echo/v4"
-	validator "gopkg.in/go-playground/validator.v9"
 )
 
-// Set a Decoder instance as a package global, because it caches
-// meta-data about structs, and an instance can be shared safely.
-var decoder = form.NewDecoder()
-
 // IRouter extracts a URL parameter value.
 type IRouter interface {
 	Param(param string) string
 }
 
+// ParamFunc is an IRouter that calls the func to extract a URL parameter
+// value.
+type ParamFunc func(param string) string
+
+// Param returns the URL parameter value.
+func (f ParamFunc) Param(param string) string {
+	return f(param)
+}
+
+// PathValue returns an IRouter for the wildcards of the pattern matched by
+// the net/http router.
+func PathValue(r *http.Request) IRouter {
+	return ParamFunc(r.PathValue)
+}
+
 // Binder contains the request bind an validator objects.
 type Binder struct {
-	validator *validator.Validate
+	validator    Validator
+	validate     *validator.Validate
+	translations []translation
+	strict       bool
+	maxMemory    int64
+	maxFileSize  int64
+	bodyDecoders map[string]BodyDecoder
+	maxBodySize  int64
+	typeDecoders map[reflect.Type]TypeDecoder
+	typeCache    sync.Map
+
+	// The decoder caches meta-data about structs so it's shared by each
+	// request.
+	decoder *form.Decoder
 }
 
 // NewBinder returns a new binder for request bind and validation.
-func NewBinder() *Binder {
-	decoder.SetTagName("json")
+func NewBinder(opts ...BinderOption) *Binder {
+	v := validator.New()
+
+	// Use the json tag names in the field errors.
+	v.RegisterTagNameFunc(validation.TagName)
+
+	b := &Binder{
+		validate:     v,
+		maxMemory:    defaultMaxMemory,
+		bodyDecoders: defaultBodyDecoders(),
+		typeDecoders: make(map[reflect.Type]TypeDecoder),
+		decoder:      form.NewDecoder(),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.decoder.SetTagName("json")
+	for t, dec := range b.typeDecoders {
+		b.decoder.RegisterCustomTypeFunc(form.DecodeCustomTypeFunc(dec), reflect.Zero(t).Interface())
+	}
 
-	return &Binder{
-		validator: validator.New(),
+	// Use the default validator unless another engine is set.
+	if b.validator == nil {
+		b.validator = newPlaygroundValidator(v, b.translations)
 	}
+
+	return b
 }
 
 // Bind will unmarshal and validate a struct from a request.
@@ -41,23 +96,97 @@ func (b *Binder) Bind(i interface{}, c echo.Context) (err error) {
 	return b.unmarshalAndValidate(i, c.Request(), c)
 }
 
+// BindRequest will unmarshal and validate a struct from a request without
+// echo. The router returns the URL parameters, like PathValue for the
+// net/http router or a ParamFunc for another router.
+func (b *Binder) BindRequest(i interface{}, r *http.Request, router IRouter) (err error) {
+	return b.unmarshalAndValidate(i, r, router)
+}
+
 // UnmarshalAndValidate will unmarshal and validate a struct using the validator.
 func (b *Binder) unmarshalAndValidate(s interface{}, r *http.Request, router IRouter) (err error) {
 	if err = b.Unmarshal(s, r, router); err != nil {
 		return
-	} else if err = b.validate(s); err != nil {
+	} else if err = b.validateStruct(r.Context(), s, acceptLanguages(r)); err != nil {
 		return
 	}
 
 	return
 }
 
-// Validate will validate a struct using the validator.
-func (b *Binder) validate(s interface{}) error {
-	return b.validator.Struct(s)
+// acceptLanguages returns the languages in the Accept-Language header of the
+// request. Each language is followed by the base language so "de-AT" can use
+// the "de" translations.
+func acceptLanguages(r *http.Request) []string {
+	langs := make([]string, 0)
+	for _, lang := range parseAccept(r.Header.Get("Accept-Language")) {
+		lang = strings.Replace(lang, "-", "_", -1)
+		langs = append(langs, lang)
+		if i := strings.Index(lang, "_"); i > 0 {
+			langs = append(langs, lang[:i])
+		}
+	}
+
+	return langs
+}
+
+// validateStruct will validate a struct using the validator. The context is
+// passed to the validation rules registered with a context. The fields of
+// the body and of an embedded struct are returned without the name of the
+// body field or the embedded struct.
+func (b *Binder) validateStruct(ctx context.Context, s interface{}, langs []string) error {
+	err := b.validator.ValidateStruct(ctx, s, langs)
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		return err
+	}
+
+	t := reflect.Indirect(reflect.ValueOf(s)).Type()
+	fields := structFields(t)
+	for _, f := range fields {
+		if _, ok := embeddedStruct(t.Field(f.index)); ok && f.in == "" {
+			for i := range ve.Errors {
+				ve.Errors[i].Field = fieldPath(ve.Errors[i].Field, f.name)
+			}
+		}
+	}
+
+	if f, ok := fields.body(); ok {
+		for i := range ve.Errors {
+			ve.Errors[i].Field = fieldPath(ve.Errors[i].Field, f.name)
+		}
+	}
+
+	return ve
+}
+
+// fieldPath returns the path to the field without the body field name.
+func fieldPath(field string, bodyName string) string {
+	if strings.HasPrefix(field, bodyName+".") {
+		return field[len(bodyName)+1:]
+	}
+
+	return field
 }
 
 // Unmarshal will perform an unmarshal on an interface using: form or JSON.
+// The body is decoded once directly into the body field, or into the struct
+// if there is no body field, and then the parameters are decoded on top using
+// the json tag names so slices, numbers, booleans, and times (RFC 3339) are
+// supported.
+//
+// A field with a default tag keeps the default if the field is absent from
+// the request. The values of a slice are separated by commas in the tag.
+//
+// If any field of the struct has an in tag, each field is only filled from
+// its declared location: path, query, header, cookie, body, or formData.
+// Fields without an in tag are then filled from the members of the body. If
+// none of the fields have an in tag, the query string values and the URL
+// parameters are matched to the top level fields and a field named Body
+// receives the body. The query string can't fill the members of the body.
+//
+// Header and cookie values are split on commas for a slice and can use an
+// HTTP date for a time.
 func (b *Binder) Unmarshal(iface interface{}, r *http.Request, router IRouter) (err error) {
 	// Check for errors.
 	v := reflect.ValueOf(iface)
@@ -65,78 +194,471 @@ func (b *Binder) Unmarshal(iface interface{}, r *http.Request, router IRouter) (
 		return errors.New("must pass a pointer, not a value")
 	}
 
-	// Load the map.
-	m := make(map[string]interface{})
+	elem := reflect.Indirect(v.Elem())
+	if elem.Kind() != reflect.Struct {
+		return errors.New("must pass a pointer to a struct")
+	}
+
+	fields := structFields(elem.Type())
+	explicit := fields.explicit()
+
+	// Set the defaults first so the values from the request take precedence.
+	defaults := structDefaults(elem.Type(), "", nil)
+	if err = b.setDefaults(iface, defaults); err != nil {
+		return fmt.Errorf("defaults could not be decoded: %v", err.Error())
+	}
+
+	// Without in tags, decode the query string first so any values in the
+	// body or the URL parameters take precedence.
+	if !explicit {
+		err = b.decodeValues(iface, queryValues(elem.Type(), fields, r.URL.Query()))
+		if err != nil {
+			return valuesError("query", err)
+		}
+	}
+
+	// A malformed body is returned after the parameters are filled.
+	bodyErr := b.unmarshalBody(iface, elem, fields, r)
+
+	// Fill the parameters last. With in tags, only fields with the matching
+	// in tag are filled so the body can't spoof a parameter and a parameter
+	// can't collide with a body field of the same name.
+	params := make(url.Values)
+	paramValues(params, elem.Type(), fields, explicit, r, router)
+
+	err = b.decodeValues(iface, params)
+	if err != nil {
+		return valuesError("parameters", err)
+	}
+
+	if err = b.setSliceDefaults(iface, elem, defaults); err != nil {
+		return fmt.Errorf("defaults could not be decoded: %v", err.Error())
+	}
+
+	return bodyErr
+}
+
+// queryValues returns the query string values of the top level fields that
+// aren't the body field. A key like "Body.admin" is dropped so the query
+// string can't fill a member of the body.
+func queryValues(t reflect.Type, fields bindFields, query url.Values) url.Values {
+	values := make(url.Values)
+	for key, vals := range query {
+		if fields.param(t, formKeyName(key)) {
+			values[key] = vals
+		}
+	}
+
+	return values
+}
+
+// paramValues adds the values of the parameters of the fields to params.
+// The fields of an embedded struct are filled like the fields of the struct
+// so a type like PageRequest can be embedded in a request.
+func paramValues(params url.Values, t reflect.Type, fields bindFields, explicit bool, r *http.Request, router IRouter) {
+	for _, f := range fields {
+		sf := t.Field(f.index)
+		if et, ok := embeddedStruct(sf); ok && f.in == "" {
+			paramValues(params, et, structFields(et), explicit, r, router)
+			continue
+		}
 
-	// Try to auto detect data type based on on the header.
-	// Header can having multiple values separated by a semicolon.
-	ct := r.Header.Get("Content-Type")
-	switch true {
-	case ct == "", strings.Contains(ct, "application/x-www-form-urlencoded"):
-		err := r.ParseForm()
+		var vals []string
+		switch {
+		case f.in == InPath, !explicit:
+			if val := router.Param(f.name); len(val) > 0 {
+				vals = []string{val}
+			}
+		case f.in == InQuery:
+			vals = r.URL.Query()[f.name]
+		case f.in == InHeader:
+			vals = headerValues(sf.Type, r.Header.Values(f.name))
+		case f.in == InCookie:
+			for _, c := range r.Cookies() {
+				if c.Name == f.name {
+					vals = append(vals, c.Value)
+				}
+			}
+			vals = headerValues(sf.Type, vals)
+		}
+
+		if len(vals) > 0 {
+			params[f.name] = vals
+		}
+	}
+}
+
+// unmarshalBody will decode the body based on the media type of the
+// Content-Type header. A body with a media type that doesn't have a body
+// decoder returns an UnsupportedMediaTypeError.
+func (b *Binder) unmarshalBody(iface interface{}, elem reflect.Value, fields bindFields, r *http.Request) (err error) {
+	mt := ""
+	if ct := r.Header.Get("Content-Type"); len(ct) > 0 {
+		mt, _, err = mime.ParseMediaType(ct)
+		if err != nil && err != mime.ErrInvalidMediaParameter {
+			return &UnsupportedMediaTypeError{MediaType: ct}
+		}
+	}
+
+	if err = b.prepareBody(r); err != nil {
+		return
+	}
+
+	switch {
+	case mt == "", mt == "application/x-www-form-urlencoded":
+		err = r.ParseForm()
+		if be := bodyError(err); be != nil {
+			return be
+		} else if err != nil {
+			return fmt.Errorf("body could not be read: %v", err.Error())
+		}
+
+		if b.isStrict(iface) {
+			err = unknownError(fields.unknownForm(elem.Type(), r.PostForm))
+			if err != nil {
+				return
+			}
+		}
+
+		err = b.unmarshalForm(iface, elem, fields, r.PostForm)
 		if err != nil {
+			return valuesError("form", err)
+		}
+	case mt == "multipart/form-data":
+		err = r.ParseMultipartForm(b.maxMemory)
+		if be := bodyError(err); be != nil {
+			return be
+		} else if err != nil {
 			return fmt.Errorf("body could not be read: %v", err.Error())
 		}
 
-		// Loop through each field to extract the URL parameter.
-		elem := reflect.Indirect(v.Elem())
-		keys := elem.Type()
-		for j := 0; j < elem.NumField(); j++ {
-			tag := keys.Field(j).Tag
-			tagvalue := tag.Get("json")
-			pathParam := router.Param(tagvalue)
-			if len(pathParam) > 0 {
-				r.PostForm.Add(tagvalue, pathParam)
+		form := r.MultipartForm
+		if b.isStrict(iface) {
+			keys := make(url.Values)
+			for key, vals := range form.Value {
+				keys[key] = vals
 			}
+			for key := range form.File {
+				keys[key] = nil
+			}
+
+			err = unknownError(fields.unknownForm(elem.Type(), keys))
+			if err != nil {
+				return
+			}
+		}
+
+		// Check the file sizes before anything is bound.
+		if err = fileSizeError(form.File, b.maxFileSize); err != nil {
+			return
 		}
 
-		// r.PostForm is a map of our POST form values.
-		err = decoder.Decode(iface, r.PostForm)
+		err = b.unmarshalForm(iface, elem, fields, form.Value)
 		if err != nil {
-			return fmt.Errorf("form could not be decoded: %v", err.Error())
+			return valuesError("form", err)
 		}
 
+		unmarshalFiles(elem, fields, form.File)
+	case mt == "application/json", strings.HasSuffix(mt, "+json"):
+		var body io.Reader = r.Body
+		defer r.Body.Close()
+
+		// Read the body into memory only to find the unknown keys.
+		if b.isStrict(iface) {
+			data, err := io.ReadAll(r.Body)
+			if be := bodyError(err); be != nil {
+				return be
+			} else if err != nil {
+				return fmt.Errorf("body could not be read: %v", err.Error())
+			}
+
+			m := make(map[string]interface{})
+			if err = decodeJSON(bytes.NewReader(data), &m); err != nil {
+				return err
+			} else if err = unknownError(fields.unknownJSON(elem.Type(), m)); err != nil {
+				return err
+			}
+
+			body = bytes.NewReader(data)
+		}
+
+		return unmarshalInto(elem, fields, func(v interface{}) error {
+			return b.decodeJSON(body, v)
+		})
+	default:
+		if dec, ok := b.bodyDecoders[mt]; ok {
+			defer r.Body.Close()
+			return unmarshalInto(elem, fields, func(v interface{}) error {
+				return decodeBody(dec, r.Body, v)
+			})
+		} else if r.ContentLength != 0 {
+			return &UnsupportedMediaTypeError{MediaType: mt}
+		}
+	}
+
+	return nil
+}
+
+// unmarshalForm will decode the form values into the body field. If there is
+// no body field, the values are decoded into the fields that are not
+// parameters.
+func (b *Binder) unmarshalForm(iface interface{}, elem reflect.Value, fields bindFields, form url.Values) error {
+	if f, ok := fields.body(); ok {
+		return b.decodeValues(elem.Field(f.index).Addr().Interface(), form)
+	}
+
+	// Only pass along the values that belong to form fields.
+	values := make(url.Values)
+	for key, vals := range form {
+		if f, ok := fields.find(formKeyName(key)); ok && f.isForm() {
+			values[key] = vals
+		}
+	}
+
+	return b.decodeValues(iface, values)
+}
+
+// unmarshalInto will decode the body directly into the body field. If there
+// is no body field, the body is decoded into the struct and then only the
+// fields that are not parameters are kept.
+func unmarshalInto(elem reflect.Value, fields bindFields, decode func(v interface{}) error) error {
+	if f, ok := fields.body(); ok {
+		return decode(elem.Field(f.index).Addr().Interface())
+	} else if !fields.explicit() {
+		return decode(elem.Addr().Interface())
+	}
+
+	// Decode into a copy of the struct without the parameter values.
+	tmp := reflect.New(elem.Type()).Elem()
+	tmp.Set(elem)
+	for _, f := range fields {
+		if !f.isForm() && tmp.Field(f.index).CanSet() {
+			tmp.Field(f.index).Set(reflect.Zero(tmp.Field(f.index).Type()))
+		}
+	}
+
+	err := decode(tmp.Addr().Interface())
+
+	for _, f := range fields {
+		if f.isForm() && elem.Field(f.index).CanSet() {
+			elem.Field(f.index).Set(tmp.Field(f.index))
+		}
+	}
+
+	return err
+}
+
+// decodeJSON will decode the JSON body into v. An empty body is allowed so
+// users can submit empty data for GET and DELETE requests. A malformed body
+// or a body with data after the JSON value returns a SyntaxError and a value
+// with the wrong type returns a ValidationError.
+func decodeJSON(body io.Reader, v interface{}) error {
+	cr := &countReader{r: body}
+	dec := json.NewDecoder(cr)
+	err := dec.Decode(v)
+	if err == nil {
+		// Only whitespace can follow the value.
+		offset := dec.InputOffset()
+		if _, err = dec.Token(); err == io.EOF {
+			return nil
+		} else if be := bodyError(err); be != nil {
+			return be
+		}
+		return &SyntaxError{Offset: offset, Err: errors.New("body has data after the JSON value")}
+	} else if err == io.EOF {
+		return nil
+	} else if be := bodyError(err); be != nil {
+		return be
+	}
+
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &se):
+		return &SyntaxError{Offset: se.Offset, Err: err}
+	case err == io.ErrUnexpectedEOF:
+		// The body ended early so the offset is the length of the body.
+		return &SyntaxError{Offset: cr.n, Err: err}
+	case errors.As(err, &te) && len(te.Field) > 0:
+		return jsonTypeError(te.Field, te.Type)
+	case errors.As(err, &te):
+		return fmt.Errorf("body must be a JSON object: %v", err.Error())
+	}
+
+	return fmt.Errorf("body could not be read: %v", err.Error())
+}
+
+// jsonTypeError returns a ValidationError for a JSON value with the wrong
+// type.
+func jsonTypeError(field string, t reflect.Type) error {
+	return &ValidationError{Errors: []FieldError{jsonTypeField(field, t)}}
+}
+
+// jsonTypeField returns the field error for a value with the wrong type.
+func jsonTypeField(field string, t reflect.Type) FieldError {
+	return FieldError{
+		Field:   field,
+		Rule:    "type",
+		Param:   jsonType(t),
+		Message: fmt.Sprintf("%s must be of type %s", field, jsonType(t)),
+	}
+}
+
+// jsonType returns the name of the JSON type for a Go type.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+
+	return "object"
+}
+
+// decodeValues will decode the URL values into an interface. The form decoder
+// panics on malformed keys like "a[" so the panic is returned as an error
+// instead since the keys come from the client.
+func (b *Binder) decodeValues(iface interface{}, values url.Values) (err error) {
+	if len(values) == 0 {
 		return nil
-	case strings.Contains(ct, "application/json"):
-		// Decode to the interface.
-		_ = json.NewDecoder(r.Body).Decode(&m)
-		r.Body.Close()
-		// if err != nil {
-		// No longer fail on an unmarshal error. This is so users can submit
-		// empty data for GET requests, yet we can still map the URL
-		// parameter by using the same logic.
-		//}
+	}
 
-		// Copy the map items to a new map.
-		mt := make(map[string]interface{})
-		for key, value := range m {
-			mt[key] = value
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
 		}
+	}()
+
+	err = b.decoder.Decode(iface, values)
 
-		// Save the map to the body to handle cases where there is a body
-		// defined.
-		m["body"] = mt
+	var de form.DecodeErrors
+	if errors.As(err, &de) {
+		return b.decodeErrors(reflect.TypeOf(iface), de)
 	}
 
-	// Loop through each field to extract the URL parameter.
-	elem := reflect.Indirect(v.Elem())
-	keys := elem.Type()
-	for j := 0; j < elem.NumField(); j++ {
-		tag := keys.Field(j).Tag
-		tagvalue := tag.Get("json")
-		pathParam := router.Param(tagvalue)
-		if len(pathParam) > 0 {
-			m[tagvalue] = pathParam
+	return err
+}
+
+// decodeErrors returns a ValidationError with a field error for each value
+// the form decoder couldn't convert. A value of a type with a TypeDecoder
+// returns the same error as a JSON value.
+func (b *Binder) decodeErrors(t reflect.Type, de form.DecodeErrors) error {
+	names := make([]string, 0, len(de))
+	for name := range de {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ve := &ValidationError{Errors: make([]FieldError, 0, len(names))}
+	for _, name := range names {
+		// A repeated value of a slice is an item of the slice.
+		ft := valueType(t, name)
+		if _, ok := b.typeDecoders[ft]; !ok && ft != nil && ft.Kind() == reflect.Slice {
+			ft = indirectType(ft.Elem())
 		}
+
+		fe := FieldError{Field: name, Rule: "type", Message: name + " is invalid"}
+		if _, ok := b.typeDecoders[ft]; ok {
+			fe = decoderField(name, ft)
+		} else if ft != nil {
+			fe = jsonTypeField(name, ft)
+		}
+		ve.Errors = append(ve.Errors, fe)
 	}
 
-	// Convert to JSON.
-	var data []byte
-	data, err = json.Marshal(m)
-	if err != nil {
-		return
+	return ve
+}
+
+// valuesError returns the error from decodeValues. A ValidationError is
+// returned as is so it's sent like the other field errors.
+func valuesError(source string, err error) error {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve
 	}
 
-	// Unmarshal to the interface from JSON.
-	return json.Unmarshal(data, &iface)
+	return fmt.Errorf("%s could not be decoded: %v", source, err.Error())
+}
+
+// valueType returns the type of the value at a form key like "a.b[0]" in a
+// type without the pointers or nil if the key doesn't match a field. The names are the json tag
+// names and embedded structs are promoted.
+func valueType(t reflect.Type, key string) reflect.Type {
+	for len(key) > 0 {
+		t = indirectType(t)
+		switch {
+		case key[0] == '.':
+			key = key[1:]
+		case key[0] == '[':
+			end := strings.IndexByte(key, ']')
+			if end < 0 || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array && t.Kind() != reflect.Map) {
+				return nil
+			}
+			t, key = t.Elem(), key[end+1:]
+		default:
+			name := formKeyName(key)
+			if t.Kind() != reflect.Struct {
+				return nil
+			}
+			if t = fieldType(t, name); t == nil {
+				return nil
+			}
+			key = key[len(name):]
+		}
+	}
+
+	return indirectType(t)
+}
+
+// fieldType returns the type of the struct field with the json name or nil
+// if there isn't one.
+func fieldType(t reflect.Type, name string) reflect.Type {
+	for j := 0; j < t.NumField(); j++ {
+		sf := t.Field(j)
+		if et, ok := embeddedStruct(sf); ok {
+			if ft := fieldType(et, name); ft != nil {
+				return ft
+			}
+			continue
+		}
+
+		n := jsonName(sf)
+		if n == "" {
+			n = sf.Name
+		}
+		if n == name {
+			return sf.Type
+		}
+	}
+
+	return nil
+}
+
+// indirectType returns the type a pointer type points to.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// countReader counts the number of bytes read.
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read will read from the reader and add to the count.
+func (c *countReader) Read(p []byte) (n int, err error) {
+	n, err = c.r.Read(p)
+	c.n += int64(n)
+	return
 }
@@ -2,15 +2,35 @@ package octane_test
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
 	"testing"
-
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	localede "github.com/go-playground/locales/de"
+	localeen "github.com/go-playground/locales/en"
+	localees "github.com/go-playground/locales/es"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/translations/en"
 	"github.com/josephspurrier/octane"
+	"github.com/josephspurrier/octane/translations/de"
+	"github.com/josephspurrier/octane/translations/es"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 // StringInt create a type alias for type int.
@@ -369,3 +389,1837 @@ func TestJSONFailureDataType(t *testing.T) {
 
 	assert.Equal(t, true, called)
 }
+
+func TestQuerySuccess(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.GET("/user/:user_id/note", func(c echo.Context) error {
+		called = true
+
+		// swagger:parameters NoteIndex
+		type request struct {
+			// in: path
+			UserID string `json:"user_id" validate:"required"`
+			// in: query
+			Limit int `json:"limit" validate:"required"`
+			// in: query
+			Sort string `json:"sort"`
+			// in: query
+			Archived bool `json:"archived"`
+			// in: query
+			Tags []string `json:"tag"`
+			// in: query
+			Since time.Time `json:"since"`
+		}
+
+		req := new(request)
+		assert.NoError(t, c.Bind(req))
+
+		assert.Equal(t, "10", req.UserID)
+		assert.Equal(t, 20, req.Limit)
+		assert.Equal(t, "created_at", req.Sort)
+		assert.Equal(t, true, req.Archived)
+		assert.Equal(t, []string{"a", "b"}, req.Tags)
+		assert.Equal(t, time.Date(2020, 11, 17, 4, 5, 6, 0, time.UTC), req.Since)
+		return nil
+	})
+
+	q := url.Values{}
+	q.Add("limit", "20")
+	q.Add("sort", "created_at")
+	q.Add("archived", "true")
+	q.Add("tag", "a")
+	q.Add("tag", "b")
+	q.Add("since", "2020-11-17T04:05:06Z")
+
+	r := httptest.NewRequest("GET", "/user/10/note?"+q.Encode(), nil)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestQueryJSONSuccess(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.POST("/user/:user_id", func(c echo.Context) error {
+		called = true
+
+		// swagger:parameters UserCreate
+		type request struct {
+			// in: path
+			UserID string `json:"user_id" validate:"required"`
+			// in: query
+			Notify bool `json:"notify"`
+			// in: body
+			Body struct {
+				// Required: true
+				FirstName string `json:"first_name" validate:"required"`
+			}
+		}
+
+		req := new(request)
+		assert.NoError(t, c.Bind(req))
+
+		assert.Equal(t, "10", req.UserID)
+		assert.Equal(t, true, req.Notify)
+		assert.Equal(t, "john", req.Body.FirstName)
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/user/10?notify=1", strings.NewReader(`{"first_name":"john"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestQueryMalformedKey(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.GET("/note", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			// in: query
+			Tags []string `json:"tag"`
+		}
+
+		req := new(request)
+		assert.NotNil(t, c.Bind(req))
+		return nil
+	})
+
+	r := httptest.NewRequest("GET", "/note?tag%5B=a", nil)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestQueryInferredBody(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	type request struct {
+		Notify bool `json:"notify"`
+		Body   struct {
+			Name  string `json:"name"`
+			Admin bool   `json:"admin"`
+		}
+	}
+
+	var req *request
+	var err error
+	e.POST("/user", func(c echo.Context) error {
+		req = new(request)
+		err = c.Bind(req)
+		return nil
+	})
+
+	// The query string can't fill the body or a member of the body.
+	r := httptest.NewRequest("POST", "/user?notify=true&Body.admin=true&Body.name=x&admin=true",
+		strings.NewReader(`{"name":"john"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, true, req.Notify)
+	assert.Equal(t, "john", req.Body.Name)
+	assert.Equal(t, false, req.Body.Admin)
+}
+
+func TestQueryTypeError(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.GET("/note", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			Limit    int       `json:"limit"`
+			Archived bool      `json:"archived"`
+			IDs      []int     `json:"id"`
+			Since    time.Time `json:"since"`
+		}
+
+		req := new(request)
+		assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{
+			{Field: "archived", Rule: "type", Param: "boolean", Message: "archived must be of type boolean"},
+			{Field: "id", Rule: "type", Param: "number", Message: "id must be of type number"},
+			{Field: "limit", Rule: "type", Param: "number", Message: "limit must be of type number"},
+		}}, c.Bind(req))
+		return nil
+	})
+
+	r := httptest.NewRequest("GET", "/note?limit=ten&archived=maybe&id=1&id=two", nil)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestInTagJSONSuccess(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.PUT("/note/:note_id", func(c echo.Context) error {
+		called = true
+
+		// swagger:parameters NoteUpdate
+		type request struct {
+			// in: path
+			NoteID string `json:"note_id" in:"path" validate:"required"`
+			// in: query
+			Notify bool `json:"notify" in:"query"`
+			// in: header
+			RequestID string `json:"X-Request-ID" in:"header"`
+			// in: cookie
+			Session string `json:"session" in:"cookie"`
+			// in: body
+			Body struct {
+				NoteID  string `json:"note_id"`
+				Message string `json:"message" validate:"required"`
+			} `in:"body"`
+		}
+
+		req := new(request)
+		assert.NoError(t, c.Bind(req))
+
+		assert.Equal(t, "10", req.NoteID)
+		assert.Equal(t, true, req.Notify)
+		assert.Equal(t, "abc", req.RequestID)
+		assert.Equal(t, "xyz", req.Session)
+		assert.Equal(t, "20", req.Body.NoteID)
+		assert.Equal(t, "hello", req.Body.Message)
+		return nil
+	})
+
+	r := httptest.NewRequest("PUT", "/note/10?notify=true", strings.NewReader(`{"note_id":"20","message":"hello","notify":false}`))
+	r.Header.Add("Content-Type", "application/json")
+	r.Header.Add("X-Request-ID", "abc")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "xyz"})
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestInTagNoSpoof(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.POST("/note", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			// in: path
+			NoteID string `json:"note_id" in:"path"`
+			// in: query
+			Limit int `json:"limit" in:"query"`
+			// in: formData
+			Message string `json:"message" in:"formData"`
+		}
+
+		req := new(request)
+		assert.NoError(t, c.Bind(req))
+
+		assert.Equal(t, "", req.NoteID)
+		assert.Equal(t, 0, req.Limit)
+		assert.Equal(t, "hello", req.Message)
+		return nil
+	})
+
+	// JSON body.
+	r := httptest.NewRequest("POST", "/note", strings.NewReader(`{"note_id":"20","limit":5,"message":"hello"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+
+	// Form body.
+	called = false
+	form := url.Values{}
+	form.Add("note_id", "20")
+	form.Add("limit", "5")
+	form.Add("message", "hello")
+
+	r = httptest.NewRequest("POST", "/note", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestInTagFormBody(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.POST("/user/:user_id", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			// in: path
+			UserID string `json:"user_id" in:"path" validate:"required"`
+			// in: body
+			Body struct {
+				UserID    string `json:"user_id"`
+				FirstName string `json:"first_name" validate:"required"`
+				Age       uint8  `json:"age" validate:"required"`
+			} `in:"body"`
+		}
+
+		req := new(request)
+		assert.NoError(t, c.Bind(req))
+
+		assert.Equal(t, "10", req.UserID)
+		assert.Equal(t, "", req.Body.UserID)
+		assert.Equal(t, "john", req.Body.FirstName)
+		assert.Equal(t, uint8(3), req.Body.Age)
+		return nil
+	})
+
+	form := url.Values{}
+	form.Add("first_name", "john")
+	form.Add("age", "3")
+
+	r := httptest.NewRequest("POST", "/user/10", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestValidationError(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.POST("/user/:user_id", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			// in: path
+			UserID string `json:"user_id" in:"path" validate:"required"`
+			// in: query
+			Limit int `json:"limit" in:"query" validate:"min=1"`
+			// in: body
+			Body struct {
+				Email    string `json:"email" validate:"required,email"`
+				Password string `json:"password" validate:"required"`
+			} `in:"body"`
+		}
+
+		req := new(request)
+		err := c.Bind(req)
+
+		var ve *octane.ValidationError
+		assert.True(t, errors.As(err, &ve))
+		assert.Equal(t, []octane.FieldError{
+			{Field: "limit", Rule: "min", Param: "1", Message: "limit must be at least 1"},
+			{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+			{Field: "password", Rule: "required", Message: "password is required"},
+		}, ve.Errors)
+		assert.Equal(t, "limit must be at least 1, email must be a valid email address, password is required", ve.Error())
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/user/10", strings.NewReader(`{"email":"john"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestValidationErrorInferred(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.POST("/user", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			Body struct {
+				Name string `json:"name" validate:"required,min=2"`
+			}
+		}
+
+		req := new(request)
+		err := c.Bind(req)
+
+		var ve *octane.ValidationError
+		assert.True(t, errors.As(err, &ve))
+		assert.Equal(t, []octane.FieldError{
+			{Field: "name", Rule: "min", Param: "2", Message: "name must be at least 2 characters"},
+		}, ve.Errors)
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/user", strings.NewReader(`{"name":"j"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestValidationTranslation(t *testing.T) {
+	tests := []struct {
+		lang     string
+		expected []string
+	}{
+		{"", []string{"email must be a valid email address", "password is a required field"}},
+		{"es-MX,es;q=0.9", []string{"email debe ser una dirección de correo electrónico válida", "password es obligatorio"}},
+		{"fr;q=0.9, de-AT;q=0.8, es;q=0.5", []string{"email muss eine gültige E-Mail-Adresse sein", "password ist ein Pflichtfeld"}},
+		{"fr", []string{"email must be a valid email address", "password is a required field"}},
+	}
+
+	e := echo.New()
+	cb := octane.NewBinder(
+		octane.WithTranslation(localeen.New(), en.RegisterDefaultTranslations),
+		octane.WithTranslation(localees.New(), es.RegisterDefaultTranslations),
+		octane.WithTranslation(localede.New(), de.RegisterDefaultTranslations),
+	)
+	e.Binder = cb
+
+	var messages []string
+	e.POST("/user", func(c echo.Context) error {
+		type request struct {
+			// in: body
+			Body struct {
+				Email    string `json:"email" validate:"required,email"`
+				Password string `json:"password" validate:"required"`
+			} `in:"body"`
+		}
+
+		req := new(request)
+		err := c.Bind(req)
+
+		var ve *octane.ValidationError
+		assert.True(t, errors.As(err, &ve))
+
+		messages = make([]string, 0)
+		for _, fe := range ve.Errors {
+			messages = append(messages, fe.Message)
+		}
+		return nil
+	})
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/user", strings.NewReader(`{"email":"john"}`))
+		r.Header.Add("Content-Type", "application/json")
+		r.Header.Add("Accept-Language", tt.lang)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		assert.Equal(t, tt.expected, messages, tt.lang)
+	}
+}
+
+func TestJSONSyntaxError(t *testing.T) {
+	tests := []struct {
+		body   string
+		offset int64
+	}{
+		{`{"first_name":"jo`, 17},
+		{`{"first_name" "john"}`, 15},
+		{`first_name=john`, 2},
+		{`{"first_name":"john"} {"first_name":"jane"}`, 21},
+		{`{"first_name":"john"}]`, 21},
+		{"{\"first_name\":\"john\"}\nx", 21},
+	}
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	var err error
+	e.POST("/user/:user_id", func(c echo.Context) error {
+		type request struct {
+			// in: path
+			UserID string `json:"user_id" in:"path" validate:"required"`
+			// in: body
+			Body struct {
+				FirstName string `json:"first_name"`
+			} `in:"body"`
+		}
+
+		req := new(request)
+		err = c.Bind(req)
+
+		assert.Equal(t, "10", req.UserID)
+		return nil
+	})
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/user/10", strings.NewReader(tt.body))
+		r.Header.Add("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		var se *octane.SyntaxError
+		if assert.True(t, errors.As(err, &se), tt.body) {
+			assert.Equal(t, tt.offset, se.Offset, tt.body)
+			assert.Equal(t, 400, se.StatusCode())
+		}
+	}
+}
+
+func TestJSONEmptyBody(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.DELETE("/note/:note_id", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			// in: path
+			NoteID string `json:"note_id" in:"path" validate:"required"`
+		}
+
+		req := new(request)
+		assert.NoError(t, c.Bind(req))
+		assert.Equal(t, "10", req.NoteID)
+		return nil
+	})
+
+	r := httptest.NewRequest("DELETE", "/note/10", strings.NewReader(" \n"))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestStrictJSON(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder(octane.WithStrict())
+	e.Binder = cb
+
+	e.POST("/user/:user_id", func(c echo.Context) error {
+		called = true
+
+		type address struct {
+			City string `json:"city"`
+		}
+
+		type request struct {
+			// in: path
+			UserID string `json:"user_id" in:"path"`
+			// in: body
+			Body struct {
+				FirstName string            `json:"first_name"`
+				Address   address           `json:"address"`
+				Phones    []address         `json:"phones"`
+				Meta      map[string]string `json:"meta"`
+			} `in:"body"`
+		}
+
+		req := new(request)
+		err := c.Bind(req)
+
+		var ve *octane.ValidationError
+		if assert.True(t, errors.As(err, &ve)) {
+			assert.Equal(t, []octane.FieldError{
+				{Field: "address.zip", Rule: "unknown", Message: "address.zip is not an allowed field"},
+				{Field: "firstname", Rule: "unknown", Message: "firstname is not an allowed field"},
+				{Field: "phones[1].number", Rule: "unknown", Message: "phones[1].number is not an allowed field"},
+				{Field: "user_id", Rule: "unknown", Message: "user_id is not an allowed field"},
+			}, ve.Errors)
+		}
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/user/10", strings.NewReader(`{
+		"firstname": "john",
+		"user_id": "20",
+		"address": {"city": "Boston", "zip": "02101"},
+		"phones": [{"city": "Boston"}, {"number": "555"}],
+		"meta": {"any": "key"}
+	}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestStrictMarker(t *testing.T) {
+	type request struct {
+		octane.Strict
+		// in: path
+		UserID string `json:"user_id" in:"path"`
+		// in: formData
+		FirstName string `json:"first_name" in:"formData"`
+	}
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	var err error
+	e.POST("/user/:user_id", func(c echo.Context) error {
+		err = c.Bind(new(request))
+		return nil
+	})
+
+	// Unknown form keys are rejected.
+	form := url.Values{}
+	form.Add("first_name", "john")
+	form.Add("firstname", "john")
+	form.Add("user_id", "20")
+
+	r := httptest.NewRequest("POST", "/user/10", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	var ve *octane.ValidationError
+	if assert.True(t, errors.As(err, &ve)) {
+		assert.Equal(t, []octane.FieldError{
+			{Field: "firstname", Rule: "unknown", Message: "firstname is not an allowed field"},
+			{Field: "user_id", Rule: "unknown", Message: "user_id is not an allowed field"},
+		}, ve.Errors)
+	}
+
+	// Known form keys are allowed.
+	r = httptest.NewRequest("POST", "/user/10?first_name=a", strings.NewReader("first_name=john"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.NoError(t, err)
+
+	// Other request structs are not strict.
+	e.POST("/note", func(c echo.Context) error {
+		type request struct {
+			Message string `json:"message"`
+		}
+		err = c.Bind(new(request))
+		return nil
+	})
+
+	r = httptest.NewRequest("POST", "/note", strings.NewReader(`{"message":"a","extra":true}`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.NoError(t, err)
+}
+
+// benchmarkRequest is a request with a path parameter, a query string value,
+// and a JSON body.
+type benchmarkRequest struct {
+	// in: path
+	UserID string `json:"user_id" in:"path" validate:"required"`
+	// in: query
+	Notify bool `json:"notify" in:"query"`
+	// in: body
+	Body struct {
+		FirstName string   `json:"first_name" validate:"required"`
+		LastName  string   `json:"last_name" validate:"required"`
+		Email     string   `json:"email" validate:"required,email"`
+		Age       int64    `json:"age"`
+		Tags      []string `json:"tags"`
+	} `in:"body"`
+}
+
+// benchmarkInferredRequest is a request without in tags.
+type benchmarkInferredRequest struct {
+	UserID string `json:"user_id" validate:"required"`
+	Body   struct {
+		FirstName string   `json:"first_name" validate:"required"`
+		LastName  string   `json:"last_name" validate:"required"`
+		Email     string   `json:"email" validate:"required,email"`
+		Age       int64    `json:"age"`
+		Tags      []string `json:"tags"`
+	}
+}
+
+const benchmarkBody = `{"first_name":"john","last_name":"smith","email":"jsmith@example.com","age":42,"tags":["a","b","c"]}`
+
+func benchmarkBind(b *testing.B, newRequest func() interface{}, opts ...octane.BinderOption) {
+	e := echo.New()
+	cb := octane.NewBinder(opts...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := httptest.NewRequest("POST", "/user/10?notify=true", strings.NewReader(benchmarkBody))
+		r.Header.Set("Content-Type", "application/json")
+		c := e.NewContext(r, httptest.NewRecorder())
+		c.SetParamNames("user_id")
+		c.SetParamValues("10")
+
+		if err := cb.Bind(newRequest(), c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindJSON(b *testing.B) {
+	benchmarkBind(b, func() interface{} { return new(benchmarkRequest) })
+}
+
+func BenchmarkBindJSONInferred(b *testing.B) {
+	benchmarkBind(b, func() interface{} { return new(benchmarkInferredRequest) })
+}
+
+func BenchmarkBindJSONStrict(b *testing.B) {
+	benchmarkBind(b, func() interface{} { return new(benchmarkRequest) }, octane.WithStrict())
+}
+
+// upperString is a string with a custom JSON unmarshaler.
+type upperString string
+
+// UnmarshalJSON will convert the string to uppercase.
+func (s *upperString) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	*s = upperString(strings.ToUpper(str))
+	return nil
+}
+
+func TestJSONDirectDecode(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.POST("/note/:note_id", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			// in: path
+			NoteID string `json:"note_id" in:"path"`
+			// in: body
+			Body struct {
+				ID      int64           `json:"id"`
+				Raw     json.RawMessage `json:"raw"`
+				Name    upperString     `json:"name"`
+				Message string          `json:"message"`
+			} `in:"body"`
+		}
+
+		req := new(request)
+		assert.NoError(t, c.Bind(req))
+
+		assert.Equal(t, "10", req.NoteID)
+		assert.Equal(t, int64(9007199254740993), req.Body.ID)
+		assert.Equal(t, `{"a": [1, 2]}`, string(req.Body.Raw))
+		assert.Equal(t, upperString("JOHN"), req.Body.Name)
+		assert.Equal(t, "hello", req.Body.Message)
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/note/10", strings.NewReader(`{"id":9007199254740993,"raw":{"a": [1, 2]},"name":"john","message":"hello"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestJSONTypeError(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	e.POST("/user/:user_id", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			// in: path
+			UserID string `json:"user_id" in:"path"`
+			// in: body
+			Body struct {
+				Address struct {
+					Zip int `json:"zip"`
+				} `json:"address"`
+			} `in:"body"`
+		}
+
+		req := new(request)
+		err := c.Bind(req)
+
+		var ve *octane.ValidationError
+		if assert.True(t, errors.As(err, &ve)) {
+			assert.Equal(t, []octane.FieldError{
+				{Field: "address.zip", Rule: "type", Param: "number", Message: "address.zip must be of type number"},
+			}, ve.Errors)
+		}
+		assert.Equal(t, "10", req.UserID)
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/user/10", strings.NewReader(`{"address":{"zip":"abc"}}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+// multipartBody returns a multipart form with the values and files.
+func multipartBody(t *testing.T, values map[string]string, files map[string][]string) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for key, val := range values {
+		assert.NoError(t, mw.WriteField(key, val))
+	}
+	for key, arr := range files {
+		for i, content := range arr {
+			fw, err := mw.CreateFormFile(key, fmt.Sprintf("%v%v.txt", key, i))
+			assert.NoError(t, err)
+			_, err = fw.Write([]byte(content))
+			assert.NoError(t, err)
+		}
+	}
+	assert.NoError(t, mw.Close())
+
+	return body, mw.FormDataContentType()
+}
+
+func TestMultipartSuccess(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder(octane.WithMaxFileSize(10))
+	e.Binder = cb
+
+	e.POST("/note/:note_id/attachment", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			// in: path
+			NoteID string `json:"note_id" in:"path" validate:"required"`
+			// in: body
+			Body struct {
+				Title       string                  `json:"title" validate:"required"`
+				Avatar      *multipart.FileHeader   `json:"avatar" validate:"required"`
+				Attachments []*multipart.FileHeader `json:"attachments" validate:"min=2"`
+			} `in:"body"`
+		}
+
+		req := new(request)
+		assert.NoError(t, c.Bind(req))
+
+		assert.Equal(t, "10", req.NoteID)
+		assert.Equal(t, "hello", req.Body.Title)
+		if assert.NotNil(t, req.Body.Avatar) {
+			assert.Equal(t, "avatar0.txt", req.Body.Avatar.Filename)
+			assert.Equal(t, int64(3), req.Body.Avatar.Size)
+		}
+		assert.Equal(t, 2, len(req.Body.Attachments))
+		return nil
+	})
+
+	body, ct := multipartBody(t, map[string]string{"title": "hello"},
+		map[string][]string{"avatar": {"abc"}, "attachments": {"a", "b"}})
+
+	r := httptest.NewRequest("POST", "/note/10/attachment", body)
+	r.Header.Add("Content-Type", ct)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestMultipartFileSize(t *testing.T) {
+	called := false
+
+	e := echo.New()
+	cb := octane.NewBinder(octane.WithMaxFileSize(5), octane.WithMaxMemory(1))
+	e.Binder = cb
+
+	e.POST("/user", func(c echo.Context) error {
+		called = true
+
+		type request struct {
+			// in: formData
+			Name string `json:"name" in:"formData" validate:"required"`
+			// in: formData
+			Avatar *multipart.FileHeader `json:"avatar" in:"formData"`
+		}
+
+		req := new(request)
+		err := c.Bind(req)
+
+		var ve *octane.ValidationError
+		if assert.True(t, errors.As(err, &ve)) {
+			assert.Equal(t, []octane.FieldError{
+				{Field: "avatar", Rule: "max_file_size", Param: "5", Message: "avatar must be at most 5 bytes"},
+			}, ve.Errors)
+		}
+		assert.Nil(t, req.Avatar)
+		return nil
+	})
+
+	body, ct := multipartBody(t, map[string]string{"name": "john"},
+		map[string][]string{"avatar": {"too large"}})
+
+	r := httptest.NewRequest("POST", "/user", body)
+	r.Header.Add("Content-Type", ct)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, true, called)
+}
+
+func TestBodyDecoders(t *testing.T) {
+	type note struct {
+		ID      int64  `json:"id"`
+		Message string `json:"message"`
+	}
+
+	msgpackBody, err := msgpack.Marshal(map[string]interface{}{"id": 9007199254740993, "message": "hello"})
+	assert.NoError(t, err)
+	cborBody, err := cbor.Marshal(map[string]interface{}{"id": 9007199254740993, "message": "hello"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"application/xml", []byte(`<note><id>9007199254740993</id><message>hello</message></note>`)},
+		{"text/xml; charset=utf-8", []byte(`<note><id>9007199254740993</id><message>hello</message></note>`)},
+		{"application/msgpack", msgpackBody},
+		{"application/cbor", cborBody},
+		{"application/merge-patch+json", []byte(`{"id":9007199254740993,"message":"hello"}`)},
+	}
+
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	type request struct {
+		// in: path
+		NoteID string `json:"note_id" in:"path"`
+		// in: body
+		Body note `in:"body"`
+	}
+
+	var req *request
+	e.PUT("/note/:note_id", func(c echo.Context) error {
+		req = new(request)
+		assert.NoError(t, c.Bind(req))
+		return nil
+	})
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("PUT", "/note/10", bytes.NewReader(tt.body))
+		r.Header.Add("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		assert.Equal(t, "10", req.NoteID, tt.contentType)
+		assert.Equal(t, note{ID: 9007199254740993, Message: "hello"}, req.Body, tt.contentType)
+	}
+}
+
+func TestXMLDecoder(t *testing.T) {
+	type author struct {
+		Email string `json:"email"`
+	}
+
+	type note struct {
+		ID        int64             `json:"id"`
+		Message   string            `json:"message"`
+		Public    bool              `json:"is_public"`
+		Tags      []string          `json:"tags"`
+		Authors   []author          `json:"authors"`
+		Author    *author           `json:"author"`
+		CreatedAt time.Time         `json:"created_at"`
+		Labels    map[string]string `json:"labels"`
+	}
+
+	expected := note{
+		ID:        9007199254740993,
+		Message:   "hello",
+		Public:    true,
+		Tags:      []string{"a", "b"},
+		Authors:   []author{{Email: "a@example.com"}},
+		Author:    &author{Email: "b@example.com"},
+		CreatedAt: time.Date(2020, 11, 17, 4, 5, 6, 0, time.UTC),
+		Labels:    map[string]string{"color": "red"},
+	}
+
+	tests := []string{
+		// The XML of DataResponse.
+		`<note><id>9007199254740993</id><message>hello</message><is_public>true</is_public>` +
+			`<tags><item>a</item><item>b</item></tags><authors><item><email>a@example.com</email></item></authors>` +
+			`<author><email>b@example.com</email></author><created_at>2020-11-17T04:05:06Z</created_at>` +
+			`<labels><color>red</color></labels></note>`,
+		// Repeated elements.
+		`<note><id>9007199254740993</id><message>hello</message><is_public>true</is_public>` +
+			`<tags>a</tags><tags>b</tags><authors><email>a@example.com</email></authors>` +
+			`<author><email>b@example.com</email></author><created_at>2020-11-17T04:05:06Z</created_at>` +
+			`<labels><color>red</color></labels></note>`,
+	}
+
+	for _, body := range tests {
+		v := note{}
+		assert.NoError(t, octane.XMLDecoder(strings.NewReader(body), &v))
+		assert.Equal(t, expected, v)
+	}
+
+	// A value with the wrong type returns a field error.
+	v := note{}
+	err := octane.XMLDecoder(strings.NewReader(`<note><author><email>a</email></author><id>ten</id></note>`), &v)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{
+		{Field: "id", Rule: "type", Param: "number", Message: "id must be of type number"},
+	}}, err)
+
+	// Only JSON numbers and booleans are accepted.
+	for _, id := range []string{"NaN", "Inf", "+1", "0x10", "1_000", "1e999"} {
+		err = octane.XMLDecoder(strings.NewReader(`<note><id>`+id+`</id></note>`), &v)
+		assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{
+			{Field: "id", Rule: "type", Param: "number", Message: "id must be of type number"},
+		}}, err, id)
+	}
+	// Malformed XML returns a syntax error.
+	err = octane.XMLDecoder(strings.NewReader(`<note><id>1</note>`), &v)
+	var se *octane.SyntaxError
+	assert.True(t, errors.As(err, &se))
+
+	for _, public := range []string{"t", "True", "1"} {
+		err = octane.XMLDecoder(strings.NewReader(`<note><is_public>`+public+`</is_public></note>`), &v)
+		assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{
+			{Field: "is_public", Rule: "type", Param: "boolean", Message: "is_public must be of type boolean"},
+		}}, err, public)
+	}
+}
+
+func TestBodyDecoderCustom(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder(
+		octane.WithBodyDecoder("text/plain", func(body io.Reader, v interface{}) error {
+			b, err := io.ReadAll(body)
+			if err != nil {
+				return err
+			}
+			*(v.(*string)) = string(b)
+			return nil
+		}),
+		octane.WithBodyDecoder("application/xml", nil),
+	)
+	e.Binder = cb
+
+	type request struct {
+		// in: body
+		Body string `in:"body"`
+	}
+
+	var err error
+	var req *request
+	e.POST("/note", func(c echo.Context) error {
+		req = new(request)
+		err = c.Bind(req)
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/note", strings.NewReader("hello"))
+	r.Header.Add("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", req.Body)
+
+	// Media types without a decoder are unsupported.
+	for _, ct := range []string{"application/xml", "application/yaml", "bad/type/"} {
+		r = httptest.NewRequest("POST", "/note", strings.NewReader("hello"))
+		r.Header.Add("Content-Type", ct)
+		w = httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		var me *octane.UnsupportedMediaTypeError
+		if assert.True(t, errors.As(err, &me), ct) {
+			assert.Equal(t, http.StatusUnsupportedMediaType, me.StatusCode())
+		}
+	}
+
+	// An empty body is allowed.
+	r = httptest.NewRequest("POST", "/note", nil)
+	r.Header.Add("Content-Type", "application/yaml")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.NoError(t, err)
+}
+
+func compressBody(t *testing.T, encoding string, data []byte) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(buf)
+	case "deflate":
+		w = zlib.NewWriter(buf)
+	}
+	_, err := w.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf
+}
+
+func TestBodyEncoding(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	type request struct {
+		// in: body
+		Body struct {
+			Message string `json:"message"`
+		} `in:"body"`
+	}
+
+	var req *request
+	var err error
+	e.POST("/note", func(c echo.Context) error {
+		req = new(request)
+		err = c.Bind(req)
+		return nil
+	})
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		r := httptest.NewRequest("POST", "/note", compressBody(t, encoding, []byte(`{"message":"hello"}`)))
+		r.Header.Add("Content-Type", "application/json")
+		r.Header.Add("Content-Encoding", encoding)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		assert.NoError(t, err, encoding)
+		assert.Equal(t, "hello", req.Body.Message, encoding)
+	}
+
+	// An empty compressed body is an empty body.
+	for _, encoding := range []string{"gzip", "deflate"} {
+		r := httptest.NewRequest("POST", "/note", http.NoBody)
+		r.Header.Add("Content-Type", "application/json")
+		r.Header.Add("Content-Encoding", encoding)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		assert.NoError(t, err, encoding)
+		assert.Equal(t, "", req.Body.Message, encoding)
+	}
+
+	r := httptest.NewRequest("POST", "/note", strings.NewReader(`{"message":"hello"}`))
+	r.Header.Add("Content-Type", "application/json")
+	r.Header.Add("Content-Encoding", "br")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.UnsupportedMediaTypeError{Encoding: "br"}, err)
+	assert.Equal(t, "unsupported content encoding: br", err.Error())
+
+	r = httptest.NewRequest("POST", "/note", strings.NewReader(`{"message":"hello"}`))
+	r.Header.Add("Content-Type", "application/json")
+	r.Header.Add("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "body could not be decompressed")
+}
+
+func TestMaxBodySize(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder(octane.WithMaxBodySize(64))
+	e.Binder = cb
+
+	type request struct {
+		Message string `json:"message"`
+	}
+
+	var err error
+	handler := func(c echo.Context) error {
+		err = c.Bind(new(request))
+		return nil
+	}
+	e.POST("/note", handler)
+	e.POST("/upload", handler, octane.MaxBodySize(1024))
+
+	large := []byte(`{"message":"` + strings.Repeat("a", 512) + `"}`)
+
+	tests := []struct {
+		path        string
+		contentType string
+		encoding    string
+		body        []byte
+		tooLarge    bool
+	}{
+		{"/note", "application/json", "", []byte(`{"message":"hello"}`), false},
+		{"/note", "application/json", "", large, true},
+		{"/note", "application/json", "gzip", large, true},
+		{"/note", "application/x-www-form-urlencoded", "", []byte("message=" + strings.Repeat("a", 512)), true},
+		{"/note", "application/xml", "", []byte("<request><message>" + strings.Repeat("a", 512) + "</message></request>"), true},
+		{"/upload", "application/json", "", large, false},
+		{"/upload", "application/json", "deflate", []byte(`{"message":"` + strings.Repeat("a", 2048) + `"}`), true},
+	}
+
+	for _, tt := range tests {
+		var body io.Reader = bytes.NewReader(tt.body)
+		if len(tt.encoding) > 0 {
+			body = compressBody(t, tt.encoding, tt.body)
+		}
+		r := httptest.NewRequest("POST", tt.path, body)
+		r.Header.Add("Content-Type", tt.contentType)
+		if len(tt.encoding) > 0 {
+			r.Header.Add("Content-Encoding", tt.encoding)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		if !tt.tooLarge {
+			assert.NoError(t, err, tt.path)
+			continue
+		}
+
+		var be *octane.BodyTooLargeError
+		if assert.True(t, errors.As(err, &be), tt.contentType) {
+			assert.Equal(t, http.StatusRequestEntityTooLarge, be.StatusCode())
+		}
+	}
+
+	// The limit also applies to multipart bodies.
+	buf, contentType := multipartBody(t, map[string]string{"message": strings.Repeat("a", 512)}, nil)
+	r := httptest.NewRequest("POST", "/note", buf)
+	r.Header.Add("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	var be *octane.BodyTooLargeError
+	assert.True(t, errors.As(err, &be))
+}
+
+func TestDefaults(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	type pagination struct {
+		Limit int `json:"limit" default:"25" validate:"max=100"`
+	}
+
+	type request struct {
+		// in: path
+		NoteID string `json:"note_id" in:"path" default:"1"`
+		// in: query
+		Sort string `json:"sort" in:"query" default:"asc" validate:"oneof=asc desc"`
+		// in: query
+		Fields []string `json:"fields" in:"query" default:"id,message"`
+		// in: body
+		Body struct {
+			pagination
+			Message string `json:"message" default:"hello"`
+			Active  bool   `json:"active" default:"true"`
+			Options struct {
+				Color string `json:"color" default:"blue"`
+			} `json:"options"`
+		} `in:"body"`
+	}
+
+	var req *request
+	var err error
+	e.POST("/note/:note_id", func(c echo.Context) error {
+		req = new(request)
+		err = c.Bind(req)
+		return nil
+	})
+
+	// The defaults are used when the fields are absent.
+	r := httptest.NewRequest("POST", "/note/10", strings.NewReader(`{}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", req.NoteID)
+	assert.Equal(t, "asc", req.Sort)
+	assert.Equal(t, []string{"id", "message"}, req.Fields)
+	assert.Equal(t, 25, req.Body.Limit)
+	assert.Equal(t, "hello", req.Body.Message)
+	assert.True(t, req.Body.Active)
+	assert.Equal(t, "blue", req.Body.Options.Color)
+
+	// The values from the request take precedence, even zero values.
+	r = httptest.NewRequest("POST", "/note/10?sort=desc&fields=id",
+		strings.NewReader(`{"limit":50,"message":"","active":false,"options":{"color":"red"}}`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, "desc", req.Sort)
+	assert.Equal(t, []string{"id"}, req.Fields)
+	assert.Equal(t, 50, req.Body.Limit)
+	assert.Equal(t, "", req.Body.Message)
+	assert.False(t, req.Body.Active)
+	assert.Equal(t, "red", req.Body.Options.Color)
+
+	// The defaults also apply to forms and are validated.
+	r = httptest.NewRequest("POST", "/note/10?sort=up", strings.NewReader(`message=hi`))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, "hi", req.Body.Message)
+	assert.Equal(t, 25, req.Body.Limit)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "sort",
+		Rule:    "oneof",
+		Param:   "asc desc",
+		Message: "sort must be one of [asc desc]",
+	}}}, err)
+}
+
+func TestDefaultsInferred(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	type request struct {
+		Page  int      `json:"page" default:"1"`
+		Tags  []string `json:"tags" default:"a,b"`
+		Title string   `json:"title" default:"untitled"`
+	}
+
+	var req *request
+	var err error
+	e.GET("/note", func(c echo.Context) error {
+		req = new(request)
+		err = c.Bind(req)
+		return nil
+	})
+
+	r := httptest.NewRequest("GET", "/note?tags=c", nil)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, &request{Page: 1, Tags: []string{"c"}, Title: "untitled"}, req)
+
+	r = httptest.NewRequest("GET", "/note?page=3&title=notes", nil)
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, &request{Page: 3, Tags: []string{"a", "b"}, Title: "notes"}, req)
+}
+
+func TestHeaderCookie(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	type request struct {
+		// in: header
+		RequestID string `json:"X-Request-ID" in:"header" validate:"required,uuid"`
+		// in: header
+		IfMatch []string `json:"If-Match" in:"header"`
+		// in: header
+		IfModifiedSince time.Time `json:"If-Modified-Since" in:"header"`
+		// in: header
+		MaxForwards int `json:"Max-Forwards" in:"header"`
+		// in: header
+		DoNotTrack *bool `json:"DNT" in:"header"`
+		// in: cookie
+		Session string `json:"session" in:"cookie" validate:"required"`
+		// in: cookie
+		Theme []string `json:"theme" in:"cookie"`
+	}
+
+	var req *request
+	var err error
+	e.GET("/note", func(c echo.Context) error {
+		req = new(request)
+		err = c.Bind(req)
+		return nil
+	})
+
+	r := httptest.NewRequest("GET", "/note", nil)
+	r.Header.Add("x-request-id", "3a6fbd9e-6b5e-4a63-9e0d-4f2bbbb1b7a4")
+	r.Header.Add("If-Match", `"a", "b"`)
+	r.Header.Add("If-Match", `"c"`)
+	r.Header.Add("If-Modified-Since", "Wed, 21 Oct 2015 07:28:00 GMT")
+	r.Header.Add("Max-Forwards", "10")
+	r.Header.Add("DNT", "1")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "xyz"})
+	r.AddCookie(&http.Cookie{Name: "theme", Value: "dark,compact"})
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	dnt := true
+	assert.NoError(t, err)
+	assert.Equal(t, &request{
+		RequestID:       "3a6fbd9e-6b5e-4a63-9e0d-4f2bbbb1b7a4",
+		IfMatch:         []string{`"a"`, `"b"`, `"c"`},
+		IfModifiedSince: time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC),
+		MaxForwards:     10,
+		DoNotTrack:      &dnt,
+		Session:         "xyz",
+		Theme:           []string{"dark", "compact"},
+	}, req)
+
+	// The headers and cookies are validated.
+	r = httptest.NewRequest("GET", "/note", nil)
+	r.Header.Add("X-Request-ID", "abc")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "X-Request-ID",
+		Rule:    "uuid",
+		Message: "X-Request-ID must be a valid UUID",
+	}, {
+		Field:   "session",
+		Rule:    "required",
+		Message: "session is required",
+	}}}, err)
+
+	// A value with the wrong type returns an error.
+	r = httptest.NewRequest("GET", "/note", nil)
+	r.Header.Add("Max-Forwards", "ten")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "xyz"})
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "Max-Forwards",
+		Rule:    "type",
+		Param:   "number",
+		Message: "Max-Forwards must be of type number",
+	}}}, err)
+}
+
+type cents int64
+
+func decodeCents(vals []string) (interface{}, error) {
+	f, err := strconv.ParseFloat(vals[0], 64)
+	if err != nil {
+		return nil, err
+	}
+	return cents(math.Round(f * 100)), nil
+}
+
+type status struct {
+	code int
+}
+
+func decodeStatus(vals []string) (interface{}, error) {
+	switch vals[0] {
+	case "open":
+		return status{code: 1}, nil
+	case "closed":
+		return status{code: 2}, nil
+	}
+	return nil, errors.New("invalid status")
+}
+
+func TestTypeDecoder(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder(
+		octane.WithTypeDecoder(decodeCents, cents(0)),
+		octane.WithTypeDecoder(decodeStatus, status{}),
+	)
+	e.Binder = cb
+
+	type item struct {
+		Price  cents  `json:"price"`
+		Status status `json:"status"`
+	}
+
+	type request struct {
+		// in: path
+		Status status `json:"status" in:"path"`
+		// in: query
+		Min cents `json:"min" in:"query"`
+		// in: header
+		Max *cents `json:"X-Max" in:"header"`
+		// in: body
+		Body struct {
+			Total cents   `json:"total"`
+			Items []item  `json:"items"`
+			Tip   *cents  `json:"tip"`
+			Note  string  `json:"note"`
+			Tags  []cents `json:"tags"`
+		} `in:"body"`
+	}
+
+	var req *request
+	var err error
+	e.POST("/order/:status", func(c echo.Context) error {
+		req = new(request)
+		err = c.Bind(req)
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/order/open?min=1.5", strings.NewReader(
+		`{"total":"12.34","items":[{"price":10.5,"status":"closed"}],"tip":null,"note":"hi","tags":["1"]}`))
+	r.Header.Add("Content-Type", "application/json")
+	r.Header.Add("X-Max", "99.99")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, status{code: 1}, req.Status)
+	assert.Equal(t, cents(150), req.Min)
+	assert.Equal(t, cents(9999), *req.Max)
+	assert.Equal(t, cents(1234), req.Body.Total)
+	assert.Equal(t, []item{{Price: 1050, Status: status{code: 2}}}, req.Body.Items)
+	assert.Nil(t, req.Body.Tip)
+	assert.Equal(t, "hi", req.Body.Note)
+	assert.Equal(t, []cents{100}, req.Body.Tags)
+
+	// The same decoders are used for forms.
+	r = httptest.NewRequest("POST", "/order/closed", strings.NewReader(`total=5&items[0].status=open`))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, status{code: 2}, req.Status)
+	assert.Equal(t, cents(500), req.Body.Total)
+	assert.Equal(t, []item{{Status: status{code: 1}}}, req.Body.Items)
+
+	// A JSON value that can't be converted returns a field error.
+	r = httptest.NewRequest("POST", "/order/open", strings.NewReader(`{"items":[{"status":"lost"}]}`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "items[0].status",
+		Rule:    "type",
+		Param:   "status",
+		Message: "items[0].status must be a valid status",
+	}}}, err)
+
+	// Each JSON value that can't be converted returns a field error in key
+	// order.
+	r = httptest.NewRequest("POST", "/order/open", strings.NewReader(
+		`{"total":"x","tags":["1","y"],"items":[{"price":"z","status":"lost"}]}`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{
+		{Field: "items[0].price", Rule: "type", Param: "cents", Message: "items[0].price must be a valid cents"},
+		{Field: "items[0].status", Rule: "type", Param: "status", Message: "items[0].status must be a valid status"},
+		{Field: "tags[1]", Rule: "type", Param: "cents", Message: "tags[1] must be a valid cents"},
+		{Field: "total", Rule: "type", Param: "cents", Message: "total must be a valid cents"},
+	}}, err)
+
+	// A parameter or a form value that can't be converted returns the same
+	// field error.
+	r = httptest.NewRequest("POST", "/order/lost?min=abc", nil)
+	r.Header.Add("X-Max", "lots")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{
+		{Field: "X-Max", Rule: "type", Param: "cents", Message: "X-Max must be a valid cents"},
+		{Field: "min", Rule: "type", Param: "cents", Message: "min must be a valid cents"},
+		{Field: "status", Rule: "type", Param: "status", Message: "status must be a valid status"},
+	}}, err)
+
+	r = httptest.NewRequest("POST", "/order/open", strings.NewReader(`items[0].status=lost&tags=1&tags=x`))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{
+		{Field: "items[0].status", Rule: "type", Param: "status", Message: "items[0].status must be a valid status"},
+		{Field: "tags", Rule: "type", Param: "cents", Message: "tags must be a valid cents"},
+	}}, err)
+
+	// A nested JSON value with the wrong type returns a field error.
+	r = httptest.NewRequest("POST", "/order/open", strings.NewReader(`{"note":5}`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "note",
+		Rule:    "type",
+		Param:   "string",
+		Message: "note must be of type string",
+	}}}, err)
+
+	// A malformed body still returns a syntax error.
+	r = httptest.NewRequest("POST", "/order/open", strings.NewReader(`{"total":`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	var se *octane.SyntaxError
+	assert.True(t, errors.As(err, &se))
+}
+
+type ownerKey struct{}
+
+type eventBody struct {
+	Name      string    `json:"name" validate:"required,slug"`
+	Password  string    `json:"password" validate:"omitempty,strong"`
+	Owner     string    `json:"owner" validate:"owner"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+func TestCustomValidation(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder(
+		octane.WithValidation("strong", func(fl validator.FieldLevel) bool {
+			return len(fl.Field().String()) >= 8
+		}),
+		octane.WithAlias("slug", "alphanum,max=20"),
+		octane.WithValidationCtx("owner", func(ctx context.Context, fl validator.FieldLevel) bool {
+			owner, _ := ctx.Value(ownerKey{}).(string)
+			return fl.Field().String() == owner
+		}),
+		octane.WithStructValidationCtx(func(ctx context.Context, sl validator.StructLevel) {
+			body := sl.Current().Interface().(eventBody)
+			if !body.EndDate.After(body.StartDate) {
+				sl.ReportError(body.EndDate, "end_date", "EndDate", "gtfield", "start_date")
+			}
+		}, eventBody{}),
+	)
+	e.Binder = cb
+
+	type request struct {
+		// in: body
+		Body eventBody `in:"body"`
+	}
+
+	var err error
+	e.POST("/event", func(c echo.Context) error {
+		err = c.Bind(new(request))
+		return nil
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			c.SetRequest(r.WithContext(context.WithValue(r.Context(), ownerKey{}, "jsmith")))
+			return next(c)
+		}
+	})
+
+	r := httptest.NewRequest("POST", "/event", strings.NewReader(`{"name":"party","password":"longenough",`+
+		`"owner":"jsmith","start_date":"2020-01-01T00:00:00Z","end_date":"2020-01-02T00:00:00Z"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+
+	r = httptest.NewRequest("POST", "/event", strings.NewReader(`{"name":"my party","password":"short",`+
+		`"owner":"jdoe","start_date":"2020-01-02T00:00:00Z","end_date":"2020-01-01T00:00:00Z"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "name",
+		Rule:    "slug",
+		Message: "name failed on the 'slug' rule",
+	}, {
+		Field:   "password",
+		Rule:    "strong",
+		Message: "password failed on the 'strong' rule",
+	}, {
+		Field:   "owner",
+		Rule:    "owner",
+		Message: "owner failed on the 'owner' rule",
+	}, {
+		Field:   "end_date",
+		Rule:    "gtfield",
+		Param:   "start_date",
+		Message: "end_date failed on the 'gtfield' rule",
+	}}}, err)
+
+	assert.Panics(t, func() {
+		octane.NewBinder(octane.WithValidation("", func(fl validator.FieldLevel) bool { return true }))
+	})
+}
+
+type engineRequest struct {
+	// in: body
+	Body struct {
+		Name string `json:"name"`
+	} `in:"body"`
+}
+
+// fakeValidator requires the name field of an engineRequest to be set.
+type fakeValidator struct {
+	langs []string
+}
+
+func (f *fakeValidator) ValidateStruct(ctx context.Context, s interface{}, langs []string) error {
+	f.langs = langs
+	if req := s.(*engineRequest); len(req.Body.Name) == 0 {
+		return &octane.ValidationError{Errors: []octane.FieldError{{
+			Field:   "Body.name",
+			Rule:    "required",
+			Message: "name is required",
+		}}}
+	}
+	return nil
+}
+
+func TestValidatorEngine(t *testing.T) {
+	e := echo.New()
+	fv := new(fakeValidator)
+	cb := octane.NewBinder(octane.WithValidator(fv))
+	e.Binder = cb
+
+	var err error
+	e.POST("/user", func(c echo.Context) error {
+		err = c.Bind(new(engineRequest))
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/user", strings.NewReader(`{}`))
+	r.Header.Add("Content-Type", "application/json")
+	r.Header.Add("Accept-Language", "de-AT, en;q=0.5")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, []string{"de_AT", "de", "en"}, fv.langs)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "name",
+		Rule:    "required",
+		Message: "name is required",
+	}}}, err)
+}
+
+func TestValidatorV10Rules(t *testing.T) {
+	e := echo.New()
+	cb := octane.NewBinder()
+	e.Binder = cb
+
+	type request struct {
+		// in: body
+		Body struct {
+			Phone string `json:"phone" validate:"omitempty,e164"`
+			ID    string `json:"id" validate:"omitempty,uuid4_rfc4122"`
+			Email string `json:"email" validate:"excluded_with=Phone"`
+		} `in:"body"`
+	}
+
+	var err error
+	e.POST("/user", func(c echo.Context) error {
+		err = c.Bind(new(request))
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/user", strings.NewReader(
+		`{"phone":"+14155552671","id":"3a6fbd9e-6b5e-4a63-9e0d-4f2bbbb1b7a4"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+
+	r = httptest.NewRequest("POST", "/user", strings.NewReader(
+		`{"phone":"555","id":"abc","email":"jsmith@example.com"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "phone",
+		Rule:    "e164",
+		Message: "phone failed on the 'e164' rule",
+	}, {
+		Field:   "id",
+		Rule:    "uuid4_rfc4122",
+		Message: "id failed on the 'uuid4_rfc4122' rule",
+	}, {
+		Field:   "email",
+		Rule:    "excluded_with",
+		Param:   "Phone",
+		Message: "email failed on the 'excluded_with' rule",
+	}}}, err)
+}
+
+func TestBindRequestPathValue(t *testing.T) {
+	type request struct {
+		// in: path
+		NoteID string `json:"note_id" in:"path" validate:"required"`
+		// in: query
+		Page int `json:"page" in:"query" default:"1"`
+		// in: body
+		Body struct {
+			Message string `json:"message" validate:"required"`
+		} `in:"body"`
+	}
+
+	cb := octane.NewBinder()
+	mux := http.NewServeMux()
+
+	var req *request
+	var err error
+	mux.HandleFunc("PUT /note/{note_id}", func(w http.ResponseWriter, r *http.Request) {
+		req = new(request)
+		err = cb.BindRequest(req, r, octane.PathValue(r))
+	})
+
+	r := httptest.NewRequest("PUT", "/note/10", strings.NewReader(`{"message":"hello"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", req.NoteID)
+	assert.Equal(t, 1, req.Page)
+	assert.Equal(t, "hello", req.Body.Message)
+
+	r = httptest.NewRequest("PUT", "/note/10", strings.NewReader(`{}`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "message",
+		Rule:    "required",
+		Message: "message is required",
+	}}}, err)
+}
+
+func TestBindRequestParamFunc(t *testing.T) {
+	type request struct {
+		// in: path
+		NoteID int `json:"note_id" in:"path"`
+	}
+
+	cb := octane.NewBinder()
+	params := map[string]string{"note_id": "10"}
+
+	req := new(request)
+	r := httptest.NewRequest("GET", "/note/10", nil)
+	err := cb.BindRequest(req, r, octane.ParamFunc(func(param string) string {
+		return params[param]
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 10, req.NoteID)
+}
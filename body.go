@@ -0,0 +1,375 @@
+package octane
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// contextKey is the type of the keys set on the request context.
+type contextKey string
+
+// keyMaxBodySize is the max body size for a route.
+const keyMaxBodySize = contextKey("max_body_size")
+
+// textUnmarshalerType is the type of a value like time.Time that is decoded
+// from text.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// BodyDecoder decodes a request body into v.
+type BodyDecoder func(body io.Reader, v interface{}) error
+
+// XMLDecoder decodes an XML body using the json tags for the element names
+// so it accepts the XML sent by DataResponse. The name of the root element is
+// ignored. The items of a slice are either repeated elements or the item
+// elements of a single element.
+func XMLDecoder(body io.Reader, v interface{}) error {
+	root, err := parseXML(body)
+	if err != nil {
+		return err
+	}
+
+	// The elements are converted to JSON using the types of v.
+	b, err := json.Marshal(xmlValue(root, reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+
+	var te *json.UnmarshalTypeError
+	if err = json.Unmarshal(b, v); errors.As(err, &te) && len(te.Field) > 0 {
+		return jsonTypeError(te.Field, te.Type)
+	}
+
+	return err
+}
+
+// xmlNode is an element of an XML body.
+type xmlNode struct {
+	name     string
+	text     string
+	children []*xmlNode
+}
+
+// parseXML returns the root element of the XML body. An empty body returns
+// io.EOF.
+func parseXML(body io.Reader) (*xmlNode, error) {
+	dec := xml.NewDecoder(body)
+
+	var root *xmlNode
+	stack := make([]*xmlNode, 0)
+	for {
+		tok, err := dec.Token()
+		var se *xml.SyntaxError
+		if err == io.EOF && root != nil {
+			return root, nil
+		} else if errors.As(err, &se) {
+			return nil, &SyntaxError{Offset: dec.InputOffset(), Err: err}
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root != nil {
+				return nil, errors.New("body has more than one root element")
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+}
+
+// xmlValue returns the value of the element as a JSON value for the type. A
+// value that isn't a JSON number or true or false is left as a string for a
+// number or a boolean so the JSON decoder returns the type error.
+func xmlValue(n *xmlNode, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	text := strings.TrimSpace(n.text)
+	switch {
+	case t == nil || t.Kind() == reflect.Interface:
+		if len(n.children) == 0 {
+			return n.text
+		}
+		return xmlObject(n, nil)
+	case reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return n.text
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return xmlObject(n, t)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return text
+		}
+		return xmlItems([]*xmlNode{n}, t.Elem())
+	case reflect.Bool:
+		if text == "true" || text == "false" {
+			return text == "true"
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if isJSONNumber(text) {
+			return json.Number(text)
+		} else if len(text) == 0 {
+			return nil
+		}
+	}
+
+	return n.text
+}
+
+// isJSONNumber returns true if the text is a JSON number literal.
+func isJSONNumber(text string) bool {
+	var v interface{}
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return false
+	}
+
+	_, ok := v.(float64)
+	return ok
+}
+
+// xmlObject returns the children of the element as the members of a JSON
+// object. The children with the same name are the items of a slice.
+func xmlObject(n *xmlNode, t reflect.Type) map[string]interface{} {
+	m := make(map[string]interface{})
+	groups := make(map[string][]*xmlNode)
+	names := make([]string, 0)
+	for _, child := range n.children {
+		if _, ok := groups[child.name]; !ok {
+			names = append(names, child.name)
+		}
+		groups[child.name] = append(groups[child.name], child)
+	}
+
+	for _, name := range names {
+		var ft reflect.Type
+		switch {
+		case t == nil:
+		case t.Kind() == reflect.Map:
+			ft = t.Elem()
+		default:
+			ft = fieldType(t, name)
+		}
+
+		nodes := groups[name]
+		if ft != nil && ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8 {
+			m[name] = xmlItems(nodes, ft.Elem())
+		} else if ft == nil && len(nodes) > 1 {
+			m[name] = xmlItems(nodes, nil)
+		} else {
+			m[name] = xmlValue(nodes[len(nodes)-1], ft)
+		}
+	}
+
+	return m
+}
+
+// xmlItems returns the items of a slice from the elements. A single element
+// that only contains item elements is the list of items like the XML sent
+// by DataResponse, otherwise each element is an item.
+func xmlItems(nodes []*xmlNode, t reflect.Type) []interface{} {
+	if len(nodes) == 1 {
+		n := nodes[0]
+		wrapped := len(n.children) > 0
+		for _, child := range n.children {
+			wrapped = wrapped && child.name == "item"
+		}
+		if wrapped {
+			nodes = n.children
+		} else if len(n.children) == 0 && len(strings.TrimSpace(n.text)) == 0 {
+			return []interface{}{}
+		}
+	}
+
+	items := make([]interface{}, 0, len(nodes))
+	for _, n := range nodes {
+		items = append(items, xmlValue(n, t))
+	}
+
+	return items
+}
+
+// MsgpackDecoder decodes a MessagePack body using the json tags.
+func MsgpackDecoder(body io.Reader, v interface{}) error {
+	dec := msgpack.NewDecoder(body)
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// CBORDecoder decodes a CBOR body using the cbor tags or the json tags.
+func CBORDecoder(body io.Reader, v interface{}) error {
+	return cbor.NewDecoder(body).Decode(v)
+}
+
+// defaultBodyDecoders returns the body decoders for each media type that are
+// registered with a new Binder. Form and JSON bodies are always supported.
+func defaultBodyDecoders() map[string]BodyDecoder {
+	return map[string]BodyDecoder{
+		"application/xml":         XMLDecoder,
+		"text/xml":                XMLDecoder,
+		"application/msgpack":     MsgpackDecoder,
+		"application/x-msgpack":   MsgpackDecoder,
+		"application/vnd.msgpack": MsgpackDecoder,
+		"application/cbor":        CBORDecoder,
+	}
+}
+
+// decodeBody will decode the body using a body decoder. An empty body is
+// allowed the same as a JSON body and a ValidationError or a SyntaxError is
+// returned as is.
+func decodeBody(dec BodyDecoder, body io.Reader, v interface{}) error {
+	err := dec(body, v)
+	if err == nil || err == io.EOF {
+		return nil
+	} else if be := bodyError(err); be != nil {
+		return be
+	}
+
+	var ve *ValidationError
+	var se *SyntaxError
+	if errors.As(err, &ve) {
+		return ve
+	} else if errors.As(err, &se) {
+		return se
+	}
+
+	return fmt.Errorf("body could not be decoded: %v", err.Error())
+}
+
+// MaxBodySize returns middleware that sets the max number of bytes the Binder
+// reads from the body of the request. It overrides the size set using
+// WithMaxBodySize so a route can allow a larger or smaller body.
+func MaxBodySize(size int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			c.SetRequest(r.WithContext(context.WithValue(r.Context(), keyMaxBodySize, size)))
+			return next(c)
+		}
+	}
+}
+
+// prepareBody will decompress the body based on the Content-Encoding header
+// and limit the number of bytes read from the decompressed body so a small
+// compressed body can't expand past the limit.
+func (b *Binder) prepareBody(r *http.Request) (err error) {
+	var body io.Reader = r.Body
+	switch enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); enc {
+	case "", "identity":
+	case "gzip", "x-gzip", "deflate":
+		// An empty body stays empty since it has no compression header.
+		br := bufio.NewReader(r.Body)
+		if _, err = br.Peek(1); err == io.EOF {
+			body = http.NoBody
+			break
+		}
+
+		if enc == "deflate" {
+			body, err = decompress(zlib.NewReader(br))
+		} else {
+			body, err = decompress(gzip.NewReader(br))
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		return &UnsupportedMediaTypeError{Encoding: enc}
+	}
+
+	limit := b.maxBodySize
+	if size, ok := r.Context().Value(keyMaxBodySize).(int64); ok {
+		limit = size
+	}
+
+	if limit > 0 {
+		body = &limitReader{r: body, limit: limit}
+	}
+
+	if body != r.Body {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{body, r.Body}
+	}
+
+	return nil
+}
+
+// decompress returns the reader of the decompressed body.
+func decompress(zr io.Reader, err error) (io.Reader, error) {
+	if err != nil {
+		return nil, fmt.Errorf("body could not be decompressed: %v", err.Error())
+	}
+
+	return zr, nil
+}
+
+// bodyError returns a BodyTooLargeError if one occurred while reading the
+// body or nil if not.
+func bodyError(err error) error {
+	var be *BodyTooLargeError
+	if errors.As(err, &be) {
+		return be
+	}
+
+	return nil
+}
+
+// limitReader returns a BodyTooLargeError after more than the limit of bytes
+// are read.
+type limitReader struct {
+	r     io.Reader
+	n     int64
+	limit int64
+}
+
+// Read will read from the reader until the limit is passed.
+func (l *limitReader) Read(p []byte) (n int, err error) {
+	if l.n > l.limit {
+		return 0, &BodyTooLargeError{Limit: l.limit}
+	}
+
+	// Read one more byte than the limit to know the limit is passed.
+	if max := l.limit - l.n + 1; int64(len(p)) > max {
+		p = p[:max]
+	}
+
+	n, err = l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limit {
+		return n - int(l.n-l.limit), &BodyTooLargeError{Limit: l.limit}
+	}
+
+	return
+}
@@ -0,0 +1,18 @@
+// Package chiparam returns the URL parameters from the go-chi router so the
+// octane Binder can be used with chi.
+package chiparam
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/josephspurrier/octane"
+)
+
+// New returns an IRouter for the URL parameters of the chi route that
+// matched the request.
+func New(r *http.Request) octane.IRouter {
+	return octane.ParamFunc(func(param string) string {
+		return chi.URLParam(r, param)
+	})
+}
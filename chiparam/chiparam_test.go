@@ -0,0 +1,42 @@
+package chiparam_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/josephspurrier/octane"
+	"github.com/josephspurrier/octane/chiparam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindRequest(t *testing.T) {
+	type request struct {
+		// in: path
+		NoteID string `json:"note_id" in:"path" validate:"required"`
+		// in: body
+		Body struct {
+			Message string `json:"message" validate:"required"`
+		} `in:"body"`
+	}
+
+	cb := octane.NewBinder()
+	router := chi.NewRouter()
+
+	var req *request
+	var err error
+	router.Put("/note/{note_id}", func(w http.ResponseWriter, r *http.Request) {
+		req = new(request)
+		err = cb.BindRequest(req, r, chiparam.New(r))
+	})
+
+	r := httptest.NewRequest("PUT", "/note/10", strings.NewReader(`{"message":"hello"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", req.NoteID)
+	assert.Equal(t, "hello", req.Body.Message)
+}
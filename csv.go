@@ -0,0 +1,262 @@
+package octane
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// csvColumn is a column of a CSV response.
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvTable contains the columns of a CSV response for the type of a row.
+// The columns of a struct are the json tag names and the keys of a map are
+// sorted, otherwise the row is a single value.
+type csvTable struct {
+	columns []csvColumn
+	keys    []string
+}
+
+// newCSVTable returns the table for the type of the rows. The keys of a map
+// are read from the rows.
+func newCSVTable(rows reflect.Value) csvTable {
+	elem := rows.Type().Elem()
+	if elem.Kind() == reflect.Interface && rows.Len() > 0 {
+		if v := reflect.Indirect(rows.Index(0).Elem()); v.IsValid() {
+			elem = v.Type()
+		}
+	}
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	switch {
+	case elem.Kind() == reflect.Struct && !isCSVValue(elem):
+		return csvTable{columns: csvColumns(elem, "", nil)}
+	case elem.Kind() == reflect.Map && elem.Key().Kind() == reflect.String:
+		return csvTable{keys: csvKeys(rows)}
+	}
+
+	return csvTable{}
+}
+
+// header returns the names of the columns.
+func (t csvTable) header() []string {
+	switch {
+	case t.columns != nil:
+		header := make([]string, 0, len(t.columns))
+		for _, col := range t.columns {
+			header = append(header, col.name)
+		}
+		return header
+	case t.keys != nil:
+		return t.keys
+	}
+
+	return []string{"value"}
+}
+
+// record returns the values of a row for each column.
+func (t csvTable) record(row reflect.Value) []string {
+	for row.Kind() == reflect.Ptr || row.Kind() == reflect.Interface {
+		if row.IsNil() {
+			row = reflect.Value{}
+			break
+		}
+		row = row.Elem()
+	}
+
+	record := make([]string, 0)
+	switch {
+	case t.columns != nil:
+		for _, col := range t.columns {
+			val, ok := reflect.Value{}, row.IsValid() && row.Kind() == reflect.Struct
+			if ok {
+				val, ok = fieldByIndex(row, col.index)
+			}
+			record = append(record, csvString(val, ok))
+		}
+	case t.keys != nil:
+		for _, key := range t.keys {
+			val := reflect.Value{}
+			if row.IsValid() && row.Kind() == reflect.Map && !row.IsNil() {
+				val = row.MapIndex(reflect.ValueOf(key).Convert(row.Type().Key()))
+			}
+			record = append(record, csvString(val, val.IsValid()))
+		}
+	default:
+		record = append(record, csvString(row, row.IsValid()))
+	}
+
+	return record
+}
+
+// encodeCSV encodes the rows of the value as CSV with a header. A slice is
+// used as the rows and a struct with one slice field uses that field, like
+// the list of notes in a response, otherwise the value is a single row. The
+// fields of a nested struct are flattened with a dot, like "author.email".
+func encodeCSV(w io.Writer, v interface{}) error {
+	rows := csvRows(reflect.ValueOf(v))
+	table := newCSVTable(rows)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(table.header()); err != nil {
+		return err
+	}
+	for i := 0; i < rows.Len(); i++ {
+		if err := cw.Write(table.record(rows.Index(i))); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRows returns the slice that contains the rows of the value.
+func csvRows(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.MakeSlice(reflect.TypeOf([]interface{}{}), 0, 0)
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case !v.IsValid():
+		return reflect.MakeSlice(reflect.TypeOf([]interface{}{}), 0, 0)
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8:
+		return v
+	case v.Kind() == reflect.Struct && !isCSVValue(v.Type()):
+		var rows reflect.Value
+		count := 0
+		for _, f := range structFields(v.Type()) {
+			fv := v.Field(f.index)
+			if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+				rows = fv
+				count++
+			}
+		}
+		if count == 1 {
+			return rows
+		}
+	}
+
+	rows := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
+	rows.Index(0).Set(v)
+	return rows
+}
+
+// csvColumns returns the columns for the fields of a struct. The fields of
+// an embedded struct are promoted.
+func csvColumns(t reflect.Type, prefix string, index []int) []csvColumn {
+	columns := make([]csvColumn, 0)
+	for j := 0; j < t.NumField(); j++ {
+		sf := t.Field(j)
+		name := jsonName(sf)
+		if name == "-" || sf.Type == strictType || (sf.PkgPath != "" && !sf.Anonymous) {
+			continue
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		idx := append(append([]int{}, index...), j)
+		switch {
+		case sf.Anonymous && name == "" && ft.Kind() == reflect.Struct:
+			columns = append(columns, csvColumns(ft, prefix, idx)...)
+			continue
+		case sf.PkgPath != "":
+			continue
+		case name == "":
+			name = sf.Name
+		}
+
+		if ft.Kind() == reflect.Struct && !isCSVValue(ft) {
+			columns = append(columns, csvColumns(ft, prefix+name+".", idx)...)
+		} else {
+			columns = append(columns, csvColumn{name: prefix + name, index: idx})
+		}
+	}
+
+	return columns
+}
+
+// csvKeys returns the keys of the map rows in order.
+func csvKeys(rows reflect.Value) []string {
+	seen := make(map[string]bool)
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.Index(i)
+		for row.Kind() == reflect.Ptr || row.Kind() == reflect.Interface {
+			row = row.Elem()
+		}
+		if row.Kind() != reflect.Map {
+			continue
+		}
+		for _, key := range row.MapKeys() {
+			seen[key.String()] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// isCSVValue returns true if a struct is a single value like a time.
+func isCSVValue(t reflect.Type) bool {
+	return t == timeType ||
+		t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) ||
+		t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType)
+}
+
+// jsonMarshalerType is the type of a json.Marshaler.
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// csvString returns the text of a value. A string is used as is and any
+// other value is encoded as JSON without the quotes.
+func csvString(v reflect.Value, ok bool) string {
+	if !ok || !v.IsValid() {
+		return ""
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.String {
+		return v.String()
+	} else if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		if err == nil {
+			return string(b)
+		}
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return ""
+	}
+
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		return s
+	}
+
+	return string(b)
+}
@@ -0,0 +1,233 @@
+package octane
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// TypeDecoder converts the values of a parameter, a form field, or a JSON
+// string to a custom type. The returned value must have the registered type.
+type TypeDecoder func(vals []string) (interface{}, error)
+
+// hasTypeDecoder returns true if a TypeDecoder is registered for the type or
+// for the type of a nested field, slice item, or pointer.
+func (b *Binder) hasTypeDecoder(t reflect.Type) bool {
+	if len(b.typeDecoders) == 0 {
+		return false
+	} else if ok, found := b.typeCache.Load(t); found {
+		return ok.(bool)
+	}
+
+	ok := b.findTypeDecoder(t, make(map[reflect.Type]bool))
+	b.typeCache.Store(t, ok)
+	return ok
+}
+
+// findTypeDecoder returns true if a TypeDecoder is registered for the type or
+// a nested type. Each type is only checked once so a recursive type stops.
+func (b *Binder) findTypeDecoder(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if _, ok := b.typeDecoders[t]; ok {
+		return true
+	} else if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		return b.findTypeDecoder(t.Elem(), seen)
+	case reflect.Struct:
+		for j := 0; j < t.NumField(); j++ {
+			sf := t.Field(j)
+			if (sf.PkgPath == "" || sf.Anonymous) && b.findTypeDecoder(sf.Type, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// decodeJSON will decode the JSON body into v. If v has a field with a
+// TypeDecoder, the JSON value of the field is passed to the TypeDecoder.
+func (b *Binder) decodeJSON(body io.Reader, v interface{}) error {
+	if !b.hasTypeDecoder(reflect.TypeOf(v)) {
+		return decodeJSON(body, v)
+	}
+
+	var raw json.RawMessage
+	if err := decodeJSON(body, &raw); err != nil || len(raw) == 0 {
+		return err
+	}
+
+	return b.decodeTyped(raw, reflect.ValueOf(v).Elem(), "")
+}
+
+// decodeTyped will decode the JSON value into v. A string is passed to the
+// TypeDecoder without quotes and any other value is passed as is. The values
+// without a TypeDecoder are decoded by the JSON decoder. The field errors of
+// the members of a struct, in key order, and of the elements of a slice are
+// returned together.
+func (b *Binder) decodeTyped(raw json.RawMessage, v reflect.Value, path string) error {
+	if dec, ok := b.typeDecoders[v.Type()]; ok {
+		if string(raw) == "null" {
+			return nil
+		}
+
+		s := string(raw)
+		var str string
+		if json.Unmarshal(raw, &str) == nil {
+			s = str
+		}
+
+		val, err := dec([]string{s})
+		if err != nil || (val != nil && reflect.TypeOf(val) != v.Type()) {
+			return decoderError(path, v.Type())
+		} else if val != nil {
+			v.Set(reflect.ValueOf(val))
+		}
+
+		return nil
+	} else if !b.hasTypeDecoder(v.Type()) {
+		return decodeRaw(raw, v, path)
+	}
+
+	var errs []FieldError
+	switch v.Kind() {
+	case reflect.Ptr:
+		if string(raw) == "null" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		} else if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return b.decodeTyped(raw, v.Elem(), path)
+	case reflect.Struct:
+		m := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return decodeRaw(raw, v, path)
+		}
+
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			val := m[key]
+			sf, ok := jsonField(v.Type(), key)
+			if !ok {
+				continue
+			}
+
+			name := key
+			if len(path) > 0 {
+				name = path + "." + key
+			}
+
+			err := b.decodeTyped(val, allocField(v, sf.Index), name)
+			if errs, err = fieldErrors(errs, err); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		var arr []json.RawMessage
+		if err := json.Unmarshal(raw, &arr); err != nil {
+			return decodeRaw(raw, v, path)
+		} else if arr == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		s := reflect.MakeSlice(v.Type(), len(arr), len(arr))
+		for i, val := range arr {
+			err := b.decodeTyped(val, s.Index(i), fmt.Sprintf("%s[%d]", path, i))
+			if errs, err = fieldErrors(errs, err); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// fieldErrors appends the field errors of a ValidationError to errs. Any
+// other error is returned.
+func fieldErrors(errs []FieldError, err error) ([]FieldError, error) {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return append(errs, ve.Errors...), nil
+	}
+
+	return errs, err
+}
+
+// allocField returns the nested field of a struct. Each nil pointer to an
+// embedded struct on the way to the field is allocated.
+func allocField(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// decodeRaw will decode a nested JSON value into v. The field of a value with
+// the wrong type includes the path to the nested value.
+func decodeRaw(raw json.RawMessage, v reflect.Value, path string) error {
+	err := json.Unmarshal(raw, v.Addr().Interface())
+	if err == nil {
+		return nil
+	}
+
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		field := path
+		if len(te.Field) > 0 && len(path) > 0 {
+			field = path + "." + te.Field
+		} else if len(te.Field) > 0 {
+			field = te.Field
+		}
+
+		if len(field) > 0 {
+			return jsonTypeError(field, te.Type)
+		}
+	}
+
+	return decodeJSON(bytes.NewReader(raw), v.Addr().Interface())
+}
+
+// decoderError returns a ValidationError for a value that a TypeDecoder
+// can't convert.
+func decoderError(path string, t reflect.Type) error {
+	return &ValidationError{Errors: []FieldError{decoderField(path, t)}}
+}
+
+// decoderField returns the field error for a value that a TypeDecoder can't
+// convert.
+func decoderField(path string, t reflect.Type) FieldError {
+	return FieldError{
+		Field:   path,
+		Rule:    "type",
+		Param:   t.Name(),
+		Message: fmt.Sprintf("%s must be a valid %s", path, t.Name()),
+	}
+}
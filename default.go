@@ -0,0 +1,131 @@
+package octane
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// defaultField contains the default tag value of a struct field.
+type defaultField struct {
+	key   string
+	index []int
+	kind  reflect.Kind
+	value string
+}
+
+// values returns the default tag value. The values of a slice or an array
+// are separated by commas.
+func (f defaultField) values() []string {
+	if f.kind == reflect.Slice || f.kind == reflect.Array {
+		return strings.Split(f.value, ",")
+	}
+
+	return []string{f.value}
+}
+
+// structDefaults returns the fields of a struct type with a default tag. The
+// fields of nested structs are included using the form key of the field like
+// "Body.page".
+func structDefaults(t reflect.Type, prefix string, index []int) []defaultField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	defaults := make([]defaultField, 0)
+	for j := 0; j < t.NumField(); j++ {
+		sf := t.Field(j)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		name := jsonName(sf)
+		if name == "-" {
+			continue
+		}
+
+		idx := append(append(make([]int, 0, len(index)+1), index...), j)
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		// The fields of an embedded struct are promoted.
+		if sf.Anonymous && name == "" {
+			if ft.Kind() == reflect.Struct {
+				defaults = append(defaults, structDefaults(ft, prefix, idx)...)
+			}
+			continue
+		} else if name == "" {
+			name = sf.Name
+		}
+
+		if def, ok := sf.Tag.Lookup("default"); ok {
+			defaults = append(defaults, defaultField{
+				key:   prefix + name,
+				index: idx,
+				kind:  ft.Kind(),
+				value: def,
+			})
+		} else if ft.Kind() == reflect.Struct && ft != timeType && ft != strictType &&
+			!reflect.PtrTo(ft).Implements(unmarshalerType) {
+			defaults = append(defaults, structDefaults(ft, prefix+name+".", idx)...)
+		}
+	}
+
+	return defaults
+}
+
+// setDefaults will set the default tag values on the struct before the
+// request is decoded so only the fields that are absent from the request
+// keep the default. Slices are skipped because the form decoder appends to
+// them.
+func (b *Binder) setDefaults(iface interface{}, defaults []defaultField) error {
+	values := make(url.Values)
+	for _, f := range defaults {
+		if f.kind != reflect.Slice {
+			values[f.key] = f.values()
+		}
+	}
+
+	return b.decodeValues(iface, values)
+}
+
+// setSliceDefaults will set the default tag values on the slices that are
+// still empty after the request is decoded.
+func (b *Binder) setSliceDefaults(iface interface{}, elem reflect.Value, defaults []defaultField) error {
+	values := make(url.Values)
+	for _, f := range defaults {
+		if f.kind != reflect.Slice {
+			continue
+		}
+
+		v, ok := fieldByIndex(elem, f.index)
+		if v = reflect.Indirect(v); ok && (!v.IsValid() || v.Len() == 0) {
+			values[f.key] = f.values()
+		}
+	}
+
+	return b.decodeValues(iface, values)
+}
+
+// fieldByIndex returns the nested field of a struct. False is returned if a
+// struct pointer on the way to the field is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
@@ -0,0 +1,197 @@
+package octane
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// statusCoder is implemented by errors that have an HTTP status code.
+type statusCoder interface {
+	StatusCode() int
+}
+
+// FieldError contains the details of a field that failed validation.
+// swagger:model
+type FieldError struct {
+	// Field contains the path to the field using the json tag names.
+	// example: email
+	// required: true
+	Field string `json:"field"`
+	// Rule contains the validation rule that failed.
+	// example: email
+	// required: true
+	Rule string `json:"rule"`
+	// Param contains the parameter of the validation rule.
+	// example: 10
+	Param string `json:"param,omitempty"`
+	// Message contains a user friendly message.
+	// example: email must be a valid email address
+	// required: true
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by the Binder when one or more fields fail
+// validation.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error returns the messages of each field.
+func (e *ValidationError) Error() string {
+	arr := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		arr = append(arr, fe.Message)
+	}
+
+	return strings.Join(arr, ", ")
+}
+
+// StatusCode returns the HTTP status code for the error.
+func (e *ValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// SyntaxError is returned by the Binder when the request body is malformed.
+type SyntaxError struct {
+	// Offset is the number of bytes read before the error.
+	Offset int64
+	// Err is the error from the decoder.
+	Err error
+}
+
+// Error returns the location of the error in the body.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("body is malformed at byte %v: %v", e.Offset, e.Err.Error())
+}
+
+// Unwrap returns the error from the decoder.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status code for the error.
+func (e *SyntaxError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// UnsupportedMediaTypeError is returned by the Binder when the request body
+// has a media type or a content encoding that can't be decoded.
+type UnsupportedMediaTypeError struct {
+	// MediaType is the media type of the Content-Type header.
+	MediaType string
+	// Encoding is the content coding of the Content-Encoding header.
+	Encoding string
+}
+
+// Error returns the media type or the content encoding.
+func (e *UnsupportedMediaTypeError) Error() string {
+	if len(e.Encoding) > 0 {
+		return fmt.Sprintf("unsupported content encoding: %v", e.Encoding)
+	}
+
+	return fmt.Sprintf("unsupported media type: %v", e.MediaType)
+}
+
+// StatusCode returns the HTTP status code for the error.
+func (e *UnsupportedMediaTypeError) StatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+// BodyTooLargeError is returned by the Binder when the request body is larger
+// than the max body size.
+type BodyTooLargeError struct {
+	// Limit is the max number of bytes allowed.
+	Limit int64
+}
+
+// Error returns the limit.
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("body must be at most %v bytes", e.Limit)
+}
+
+// StatusCode returns the HTTP status code for the error.
+func (e *BodyTooLargeError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// HTTPError is an error that a handler returns instead of sending the
+// response. The ErrorHandler or ErrorResponse sends the message and the
+// details with the status code. The cause is only logged so it can contain
+// internal information like a database error.
+type HTTPError struct {
+	// Status is the HTTP status code.
+	Status int
+	// Message is the user friendly message that is sent to the client.
+	Message string
+	// Cause is the internal error that is logged but never sent.
+	Cause error
+	// Details is sent in the details member if it's not nil.
+	Details interface{}
+}
+
+// NewHTTPError returns an HTTPError with the status code and the message
+// that is sent to the client.
+func NewHTTPError(status int, message string) *HTTPError {
+	return &HTTPError{
+		Status:  status,
+		Message: message,
+	}
+}
+
+// InternalError returns an HTTPError for an unexpected error. The cause is
+// logged and the client only receives a generic message.
+func InternalError(cause error) *HTTPError {
+	return NewHTTPError(http.StatusInternalServerError, "").WithCause(cause)
+}
+
+// WithCause sets the internal error and returns the HTTPError.
+func (e *HTTPError) WithCause(cause error) *HTTPError {
+	e.Cause = cause
+	return e
+}
+
+// WithDetails sets the details and returns the HTTPError.
+func (e *HTTPError) WithDetails(details interface{}) *HTTPError {
+	e.Details = details
+	return e
+}
+
+// Error returns the message with the cause.
+func (e *HTTPError) Error() string {
+	msg := e.PublicMessage()
+	if e.Cause != nil {
+		return fmt.Sprintf("%v: %v", msg, e.Cause.Error())
+	}
+
+	return msg
+}
+
+// Unwrap returns the cause.
+func (e *HTTPError) Unwrap() error {
+	return e.Cause
+}
+
+// StatusCode returns the HTTP status code for the error. A status code that
+// isn't an error is sent as 500.
+func (e *HTTPError) StatusCode() int {
+	if e.Status < 400 || e.Status > 599 {
+		return http.StatusInternalServerError
+	}
+
+	return e.Status
+}
+
+// PublicMessage returns the message that is safe to send to the client. An
+// empty message uses the status text, or a generic message for a server
+// error.
+func (e *HTTPError) PublicMessage() string {
+	switch {
+	case len(e.Message) > 0:
+		return e.Message
+	case e.StatusCode() >= 500:
+		return "an unexpected error occurred"
+	}
+
+	return http.StatusText(e.StatusCode())
+}
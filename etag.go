@@ -0,0 +1,201 @@
+package octane
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewETag returns a strong ETag for a version of a resource, like a
+// revision number or a hash of the stored content. The version can't
+// contain a double quote.
+func NewETag(version string) string {
+	return `"` + version + `"`
+}
+
+// NewWeakETag returns a weak ETag for a version of a resource. A weak ETag
+// is only used with If-None-Match so it can't be used to prevent a lost
+// update with If-Match.
+func NewWeakETag(version string) string {
+	return `W/"` + version + `"`
+}
+
+// HashETag returns a strong ETag from a hash of the content.
+func HashETag(b []byte) string {
+	sum := sha256.Sum256(b)
+	return NewETag(hex.EncodeToString(sum[:16]))
+}
+
+// etagMatch returns true if the ETag is in the list of an If-Match or an
+// If-None-Match header. The weak comparison ignores the W/ prefix and the
+// strong comparison doesn't match a weak ETag.
+func etagMatch(header string, etag string, weak bool) bool {
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		switch {
+		case value == "*":
+			return true
+		case weak && strings.TrimPrefix(value, "W/") == opaque:
+			return true
+		case !weak && value == etag && !strings.HasPrefix(etag, "W/"):
+			return true
+		}
+	}
+
+	return false
+}
+
+// modifiedSince returns true if the resource was modified after the HTTP
+// date of the header. An invalid date is ignored.
+func modifiedSince(header string, lastModified time.Time) (bool, bool) {
+	if len(header) == 0 || lastModified.IsZero() {
+		return false, false
+	}
+
+	t, err := http.ParseTime(header)
+	if err != nil {
+		return false, false
+	}
+
+	return lastModified.Truncate(time.Second).After(t), true
+}
+
+// Preconditions sets the ETag and the Last-Modified headers of the
+// resource and evaluates the conditional headers of the request in the order
+// of RFC 9110. A GET or a HEAD that matches If-None-Match or that was not
+// modified since If-Modified-Since sends 304 Not Modified. A request that
+// fails If-Match or If-Unmodified-Since, or any other method that matches
+// If-None-Match, sends 412 Precondition Failed. An empty etag or a zero
+// lastModified is not set or evaluated.
+//
+// False is returned if a response was sent so the handler should return the
+// error without changing the resource.
+func (c *ResponseJSON) Preconditions(etag string, lastModified time.Time) (bool, error) {
+	return c.preconditions(etag, []string{etag}, lastModified)
+}
+
+// preconditions evaluates the conditional headers like Preconditions. The
+// If-Match header can match any of the ETags of the resource.
+func (c *ResponseJSON) preconditions(etag string, etags []string, lastModified time.Time) (bool, error) {
+	req := c.Request()
+	header := c.Response().Header()
+	if len(etag) > 0 {
+		header.Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		header.Set(echo.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	read := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	if im := req.Header.Get("If-Match"); len(im) > 0 && len(etag) > 0 {
+		matched := false
+		for _, e := range etags {
+			matched = matched || etagMatch(im, e, false)
+		}
+		if !matched {
+			return false, c.preconditionFailed()
+		}
+	} else if modified, ok := modifiedSince(req.Header.Get("If-Unmodified-Since"), lastModified); ok && modified {
+		return false, c.preconditionFailed()
+	}
+
+	if inm := req.Header.Get("If-None-Match"); len(inm) > 0 && len(etag) > 0 {
+		if !etagMatch(inm, etag, true) {
+			return true, nil
+		} else if read {
+			return false, c.NoContent(http.StatusNotModified)
+		}
+		return false, c.preconditionFailed()
+	} else if read {
+		if modified, ok := modifiedSince(req.Header.Get(echo.HeaderIfModifiedSince), lastModified); ok && !modified {
+			return false, c.NoContent(http.StatusNotModified)
+		}
+	}
+
+	return true, nil
+}
+
+// preconditionFailed sends 412 Precondition Failed.
+func (c *ResponseJSON) preconditionFailed() error {
+	return c.MessageResponse("the resource was changed by another request", http.StatusPreconditionFailed)
+}
+
+// ETagDataResponse sends content like DataResponse with a strong ETag from a
+// hash of the encoded content and the lastModified time. Each format has its
+// own ETag. The conditional headers are evaluated like Preconditions so if
+// the client has the content, 304 Not Modified is sent without it. A zero
+// lastModified is not set or evaluated.
+func (c *ResponseJSON) ETagDataResponse(code int, i interface{}, lastModified time.Time) error {
+	res := c.Response()
+	res.Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+	mediaType, enc, ok := negotiate(c.Request().Header.Get(echo.HeaderAccept), responseEncoders)
+	if !ok {
+		return c.MessageResponse(notAcceptable(responseEncoders), http.StatusNotAcceptable)
+	}
+
+	// The content is encoded first so the hash can be sent in the headers.
+	b, err := encodeData(enc, code, i)
+	if err != nil {
+		return err
+	}
+
+	if ok, err := c.Preconditions(HashETag(b), lastModified); !ok {
+		return err
+	}
+
+	setContentType(res, mediaType)
+	res.WriteHeader(code)
+	_, err = res.Write(b)
+	return err
+}
+
+// VersionETag returns a strong ETag for a version of a resource, like a
+// revision number, in the format of the Accept header so each format has its
+// own ETag. JSON is used if none of the formats are acceptable. The version
+// can't contain a double quote.
+func (c *ResponseJSON) VersionETag(version string) string {
+	_, enc, ok := negotiate(c.Request().Header.Get(echo.HeaderAccept), responseEncoders)
+	if !ok {
+		enc = responseEncoders[0]
+	}
+
+	return versionETag(version, enc)
+}
+
+// VersionPreconditions evaluates the conditional headers like Preconditions
+// with the ETag from VersionETag. If-Match matches the version in any format
+// so a client can change the resource with the ETag of the format it read,
+// while If-None-Match only matches the format of the Accept header.
+func (c *ResponseJSON) VersionPreconditions(version string, lastModified time.Time) (bool, error) {
+	etags := make([]string, 0, len(responseEncoders))
+	for _, enc := range responseEncoders {
+		etags = append(etags, versionETag(version, enc))
+	}
+
+	return c.preconditions(c.VersionETag(version), etags, lastModified)
+}
+
+// versionETag returns the ETag of a version in the format of the encoder
+// like "3.json".
+func versionETag(version string, enc responseEncoder) string {
+	format := enc.mediaType[strings.Index(enc.mediaType, "/")+1:]
+	return NewETag(version + "." + strings.TrimPrefix(format, "x-"))
+}
+
+// encodeData returns the content encoded like DataResponse.
+func encodeData(enc responseEncoder, code int, i interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := enc.encode(buf, dataValue(enc, code, i, nil)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
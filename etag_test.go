@@ -0,0 +1,152 @@
+package octane_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// conditionalContext returns a ResponseJSON for a request with the headers.
+func conditionalContext(method string, headers map[string]string) (*octane.ResponseJSON, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, "/note/1", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+
+	return &octane.ResponseJSON{Context: e.NewContext(req, rec)}, rec
+}
+
+func TestETag(t *testing.T) {
+	assert.Equal(t, `"v2"`, octane.NewETag("v2"))
+	assert.Equal(t, `W/"v2"`, octane.NewWeakETag("v2"))
+	assert.Equal(t, `"2cf24dba5fb0a30e26e83b2ac5b9e29e"`, octane.HashETag([]byte("hello")))
+}
+
+func TestPreconditions(t *testing.T) {
+	etag := octane.NewETag("v2")
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		ok      bool
+		code    int
+	}{
+		{"no conditions", http.MethodGet, nil, true, http.StatusOK},
+		{"if-none-match", http.MethodGet, map[string]string{"If-None-Match": `"v1", W/"v2"`}, false, http.StatusNotModified},
+		{"if-none-match changed", http.MethodGet, map[string]string{"If-None-Match": `"v1"`}, true, http.StatusOK},
+		{"if-none-match ignores if-modified-since", http.MethodGet,
+			map[string]string{"If-None-Match": `"v1"`, "If-Modified-Since": after}, true, http.StatusOK},
+		{"if-modified-since", http.MethodHead, map[string]string{"If-Modified-Since": after}, false, http.StatusNotModified},
+		{"if-modified-since changed", http.MethodGet, map[string]string{"If-Modified-Since": before}, true, http.StatusOK},
+		{"if-none-match on put", http.MethodPut, map[string]string{"If-None-Match": "*"}, false, http.StatusPreconditionFailed},
+		{"if-match", http.MethodPut, map[string]string{"If-Match": `"v2"`}, true, http.StatusOK},
+		{"if-match any", http.MethodDelete, map[string]string{"If-Match": "*"}, true, http.StatusOK},
+		{"if-match changed", http.MethodPut, map[string]string{"If-Match": `"v1"`}, false, http.StatusPreconditionFailed},
+		{"if-match weak", http.MethodPut, map[string]string{"If-Match": `W/"v2"`}, false, http.StatusPreconditionFailed},
+		{"if-match ignores if-unmodified-since", http.MethodPut,
+			map[string]string{"If-Match": `"v2"`, "If-Unmodified-Since": before}, true, http.StatusOK},
+		{"if-unmodified-since", http.MethodDelete, map[string]string{"If-Unmodified-Since": after}, true, http.StatusOK},
+		{"if-unmodified-since changed", http.MethodDelete, map[string]string{"If-Unmodified-Since": before}, false, http.StatusPreconditionFailed},
+		{"invalid date", http.MethodDelete, map[string]string{"If-Unmodified-Since": "yesterday"}, true, http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, rec := conditionalContext(tc.method, tc.headers)
+			ok, _ := c.Preconditions(etag, modified)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.code, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", rec.Header().Get("Last-Modified"))
+		})
+	}
+
+	// The failed precondition is sent as a message.
+	c, rec := conditionalContext(http.MethodPut, map[string]string{"If-Match": `"v1"`})
+	_, err := c.Preconditions(etag, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", rec.Header().Get("Last-Modified"))
+	assert.JSONEq(t, `{
+		"message": "the resource was changed by another request",
+		"status_code": 412,
+		"status_message": "Precondition Failed"
+	}`, rec.Body.String())
+}
+
+func TestETagDataResponse(t *testing.T) {
+	data := negotiateAuthor{Email: "jsmith@example.com"}
+
+	c, rec := conditionalContext(http.MethodGet, nil)
+	assert.Nil(t, c.ETagDataResponse(http.StatusOK, data, time.Time{}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Result().Header.Get("ETag")
+	assert.Equal(t, octane.HashETag(rec.Body.Bytes()), etag)
+
+	// Each format has its own ETag.
+	c, rec = conditionalContext(http.MethodGet, map[string]string{echo.HeaderAccept: "text/csv"})
+	assert.Nil(t, c.ETagDataResponse(http.StatusOK, data, time.Time{}))
+	assert.NotEqual(t, etag, rec.Result().Header.Get("ETag"))
+
+	c, rec = conditionalContext(http.MethodGet, map[string]string{"If-None-Match": etag})
+	assert.Nil(t, c.ETagDataResponse(http.StatusOK, data, time.Time{}))
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, 0, rec.Body.Len())
+
+	// The last modified time is sent and evaluated.
+	modified := time.Date(2020, 11, 17, 4, 5, 6, 0, time.UTC)
+	c, rec = conditionalContext(http.MethodGet, map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)})
+	assert.Nil(t, c.ETagDataResponse(http.StatusOK, data, modified))
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, modified.Format(http.TimeFormat), rec.Result().Header.Get(echo.HeaderLastModified))
+
+	c, rec = conditionalContext(http.MethodGet, map[string]string{"If-Unmodified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)})
+	assert.Nil(t, c.ETagDataResponse(http.StatusOK, data, modified))
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+}
+
+func TestVersionPreconditions(t *testing.T) {
+	// Each format has its own ETag.
+	c, _ := conditionalContext(http.MethodGet, map[string]string{echo.HeaderAccept: "application/xml"})
+	xmlETag := c.VersionETag("3")
+	assert.Equal(t, `"3.xml"`, xmlETag)
+	c, _ = conditionalContext(http.MethodGet, map[string]string{echo.HeaderAccept: "image/png"})
+	assert.Equal(t, `"3.json"`, c.VersionETag("3"))
+
+	// If-None-Match only matches the same format.
+	c, rec := conditionalContext(http.MethodGet, map[string]string{"If-None-Match": xmlETag})
+	ok, err := c.VersionPreconditions("3", time.Time{})
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, `"3.json"`, rec.Result().Header.Get("ETag"))
+
+	c, rec = conditionalContext(http.MethodGet, map[string]string{echo.HeaderAccept: "text/xml", "If-None-Match": xmlETag})
+	ok, err = c.VersionPreconditions("3", time.Time{})
+	assert.False(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	// A PUT in another format passes If-Match with the ETag of the format it
+	// read.
+	c, rec = conditionalContext(http.MethodPut, map[string]string{"If-Match": xmlETag})
+	ok, err = c.VersionPreconditions("3", time.Time{})
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, `"3.json"`, rec.Result().Header.Get("ETag"))
+
+	// An ETag of another version fails.
+	c, rec = conditionalContext(http.MethodPut, map[string]string{"If-Match": xmlETag})
+	ok, _ = c.VersionPreconditions("4", time.Time{})
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+}
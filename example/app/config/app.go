@@ -1,18 +1,28 @@
 package config
 
 import (
+	"net/http"
 	"time"
 
+	localede "github.com/go-playground/locales/de"
+	localeen "github.com/go-playground/locales/en"
+	localees "github.com/go-playground/locales/es"
+	"github.com/go-playground/validator/v10/translations/en"
 	"github.com/josephspurrier/octane"
 	"github.com/josephspurrier/octane/example/app"
 	"github.com/josephspurrier/octane/example/app/endpoint"
 	"github.com/josephspurrier/octane/example/app/lib/passhash"
 	"github.com/josephspurrier/octane/example/app/lib/webtoken"
 	"github.com/josephspurrier/octane/example/app/middleware/jwt"
+	"github.com/josephspurrier/octane/translations/de"
+	"github.com/josephspurrier/octane/translations/es"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// maxBodySize is the max number of bytes read from a request body.
+const maxBodySize = 1 << 20
+
 // Config .
 func Config() *echo.Echo {
 	e := echo.New()
@@ -26,8 +36,18 @@ func Config() *echo.Echo {
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
-	// Use Go Playground Validator.
-	e.Binder = octane.NewBinder()
+	// Send the errors as problem details to clients that accept them.
+	e.Use(octane.ProblemDetails(octane.ProblemAccept))
+
+	// Use Go Playground Validator with the validation messages in English,
+	// Spanish, and German. English is used when the Accept-Language header
+	// doesn't match.
+	e.Binder = octane.NewBinder(
+		octane.WithTranslation(localeen.New(), en.RegisterDefaultTranslations),
+		octane.WithTranslation(localees.New(), es.RegisterDefaultTranslations),
+		octane.WithTranslation(localede.New(), de.RegisterDefaultTranslations),
+		octane.WithMaxBodySize(maxBodySize),
+	)
 
 	// Connect the services.
 	// Any changes here need to be also be made in the app/context.go file.
@@ -47,23 +67,139 @@ func Config() *echo.Echo {
 	}, ac.Webtoken, *ac)
 	e.Use(token.Handler())
 
-	// Set the default error handler so all errors are handled outside of this function.
-	e.HTTPErrorHandler = func(err error, c echo.Context) {
-		c.Logger().Error(err)
-	}
+	// Send the errors returned from the handlers and log them with the request.
+	e.HTTPErrorHandler = octane.ErrorHandler()
+
+	// Describe the endpoints in the OpenAPI document as they are added.
+	api := octane.NewOpenAPI(octane.OpenAPIInfo{
+		Title:   "Octane API",
+		Version: "1.0.0",
+	})
+	api.AddSecurityScheme("token", octane.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	})
 
 	// Endpoints.
-	e.GET("/api/v1/healthcheck", ac.HandlerFunc(endpoint.Healthcheck))
-	e.POST("/api/v1/login", ac.HandlerFunc(endpoint.Login))
-	e.POST("/api/v1/register", ac.HandlerFunc(endpoint.Register))
-	e.POST("/api/v1/note", ac.HandlerFunc(endpoint.NoteCreate))
-	e.GET("/api/v1/note", ac.HandlerFunc(endpoint.NoteIndex))
-	e.GET("/api/v1/note/:note_id", ac.HandlerFunc(endpoint.NoteShow))
-	e.PUT("/api/v1/note/:note_id", ac.HandlerFunc(endpoint.NoteUpdate))
-	e.DELETE("/api/v1/note/:note_id", ac.HandlerFunc(endpoint.NoteDestroy))
+	api.Add(e, octane.Route{
+		Method:      http.MethodGet,
+		Path:        "/api/v1/healthcheck",
+		OperationID: "HealthcheckGET",
+		Tags:        []string{"healthcheck"},
+		Summary:     "Returns an OK message to show the application is functioning.",
+		Responses: map[int]interface{}{
+			http.StatusOK: octane.OKResponse{},
+		},
+	}, ac.HandlerFunc(endpoint.Healthcheck))
+	api.Add(e, octane.Route{
+		Method:      http.MethodPost,
+		Path:        "/api/v1/login",
+		OperationID: "UserLogin",
+		Tags:        []string{"authentication"},
+		Summary:     "Return a token after verifying the login information.",
+		Request:     endpoint.LoginRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:                  endpoint.LoginResponse{},
+			http.StatusBadRequest:          octane.BadRequestResponse{},
+			http.StatusUnprocessableEntity: octane.ValidationErrorResponse{},
+			http.StatusInternalServerError: octane.InternalServerErrorResponse{},
+		},
+	}, ac.HandlerFunc(endpoint.Login))
+	api.Add(e, octane.Route{
+		Method:      http.MethodPost,
+		Path:        "/api/v1/register",
+		OperationID: "UserRegister",
+		Tags:        []string{"authentication"},
+		Summary:     "Create a user in the system.",
+		Request:     endpoint.RegisterRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:             endpoint.RegisterResponse{},
+			http.StatusBadRequest:          octane.BadRequestResponse{},
+			http.StatusUnprocessableEntity: octane.ValidationErrorResponse{},
+			http.StatusInternalServerError: octane.InternalServerErrorResponse{},
+		},
+	}, ac.HandlerFunc(endpoint.Register))
+	api.Add(e, octane.Route{
+		Method:      http.MethodPost,
+		Path:        "/api/v1/note",
+		OperationID: "NoteCreate",
+		Tags:        []string{"note"},
+		Summary:     "Create a note for the current user.",
+		Security:    []string{"token"},
+		Request:     endpoint.NoteCreateRequest{},
+		Responses:   noteResponses(http.StatusCreated, endpoint.NoteCreateResponse{}),
+	}, endpoint.NoteCreate(ac))
+	api.Add(e, octane.Route{
+		Method:      http.MethodGet,
+		Path:        "/api/v1/note",
+		OperationID: "NoteIndex",
+		Tags:        []string{"note"},
+		Summary:     "Return a page of notes for the current user.",
+		Security:    []string{"token"},
+		Request:     endpoint.NoteIndexRequest{},
+		Responses:   noteResponses(http.StatusOK, endpoint.NoteIndexResponse{}),
+	}, ac.HandlerFunc(endpoint.NoteIndex))
+	api.Add(e, octane.Route{
+		Method:      http.MethodGet,
+		Path:        "/api/v1/note/:note_id",
+		OperationID: "NoteShow",
+		Tags:        []string{"note"},
+		Summary:     "Return a note for the current user.",
+		Security:    []string{"token"},
+		Request:     endpoint.NoteShowRequest{},
+		Responses:   noteResponses(http.StatusOK, endpoint.NoteShowResponse{}, http.StatusNotModified),
+	}, ac.HandlerFunc(endpoint.NoteShow))
+	api.Add(e, octane.Route{
+		Method:      http.MethodPut,
+		Path:        "/api/v1/note/:note_id",
+		OperationID: "NoteUpdate",
+		Tags:        []string{"note"},
+		Summary:     "Update a note for the current user.",
+		Security:    []string{"token"},
+		Request:     endpoint.NoteUpdateRequest{},
+		Responses:   noteResponses(http.StatusOK, octane.OKResponse{}, http.StatusPreconditionFailed),
+	}, ac.HandlerFunc(endpoint.NoteUpdate))
+	api.Add(e, octane.Route{
+		Method:      http.MethodDelete,
+		Path:        "/api/v1/note/:note_id",
+		OperationID: "NoteDestroy",
+		Tags:        []string{"note"},
+		Summary:     "Delete a note for the current user.",
+		Security:    []string{"token"},
+		Request:     endpoint.NoteDestroyRequest{},
+		Responses:   noteResponses(http.StatusOK, octane.OKResponse{}, http.StatusPreconditionFailed),
+	}, ac.HandlerFunc(endpoint.NoteDestroy))
+
+	// Validate the requests against the document of the endpoints.
+	e.Use(Spec(e.Logger, settings, api))
 
 	// Static routes.
+	e.GET("/swagger/openapi.json", api.JSONHandler())
+	e.GET("/swagger/openapi.yaml", api.YAMLHandler())
 	e.Static("/swagger/*", "swaggerui")
 
 	return e
 }
+
+// noteResponses returns the responses shared by the note endpoints with the
+// response for the status code and the conditional request status codes.
+func noteResponses(code int, resp interface{}, conditional ...int) map[int]interface{} {
+	responses := map[int]interface{}{
+		code:                           resp,
+		http.StatusBadRequest:          octane.BadRequestResponse{},
+		http.StatusUnauthorized:        octane.UnauthorizedResponse{},
+		http.StatusUnprocessableEntity: octane.ValidationErrorResponse{},
+		http.StatusInternalServerError: octane.InternalServerErrorResponse{},
+	}
+	for _, c := range conditional {
+		switch c {
+		case http.StatusNotModified:
+			responses[c] = nil
+		case http.StatusPreconditionFailed:
+			responses[c] = octane.PreconditionFailedResponse{}
+		}
+	}
+
+	return responses
+}
@@ -63,4 +63,8 @@ CREATE TABLE note (
     PRIMARY KEY (id)
 );
 --rollback DROP TABLE user;
+
+--changeset josephspurrier:5
+ALTER TABLE note ADD COLUMN version INT UNSIGNED NOT NULL DEFAULT 1 AFTER message;
+--rollback ALTER TABLE note DROP COLUMN version;
 `
@@ -9,7 +9,8 @@ import (
 type Settings struct {
 	Port           int    `env:"API_PORT" default:"8080"`
 	Secret         string `env:"API_SECRET" default:"TA8tALZAvLVLo4ToI44xF/nF6IyrRNOR6HSfpno/81M="`
-	SessionTimeout int    `env:"API_SESSION_TIMEOUT" default:"480"` // 480 min = 8 hours.
+	SessionTimeout int    `env:"API_SESSION_TIMEOUT" default:"480"`  // 480 min = 8 hours.
+	SpecResponses  bool   `env:"API_SPEC_RESPONSES" default:"false"` // Validate the responses in tests.
 }
 
 // LoadEnv will load the settings from the environment variables or defaults.
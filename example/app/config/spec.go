@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+)
+
+// Spec returns the middleware that validates the requests against the
+// OpenAPI document of the endpoints so the document and the validation
+// can't drift apart. Add it after the endpoints are described. The
+// responses are also validated when SpecResponses is set.
+func Spec(l echo.Logger, s *Settings, api *octane.OpenAPI) echo.MiddlewareFunc {
+	b, err := api.MarshalJSON()
+	if err != nil {
+		l.Fatalf("error creating the spec: %v", err.Error())
+	}
+
+	spec, err := octane.NewSpec(b)
+	if err != nil {
+		l.Fatalf(err.Error())
+	}
+
+	opts := []octane.SpecOption{octane.WithSpecMaxBodySize(maxBodySize)}
+	if s.SpecResponses {
+		opts = append(opts, octane.WithResponseValidation())
+	}
+
+	return spec.Middleware(opts...)
+}
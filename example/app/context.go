@@ -46,6 +46,11 @@ func (ctx *Context) SetUserID(val string) {
 
 // UserID gets the user ID from the context.
 func (ctx *Context) UserID() (string, bool) {
-	val, ok := ctx.Request().Context().Value(KeyUserID).(string)
+	return UserID(ctx.Request().Context())
+}
+
+// UserID gets the user ID from the context of a request.
+func UserID(ctx context.Context) (string, bool) {
+	val, ok := ctx.Value(KeyUserID).(string)
 	return val, ok
 }
@@ -1,12 +1,17 @@
 package endpoint
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/josephspurrier/octane"
 	"github.com/josephspurrier/octane/example/app"
 	"github.com/josephspurrier/octane/example/app/lib/structcopy"
 	"github.com/josephspurrier/octane/example/app/store"
+	"github.com/labstack/echo/v4"
 )
 
 // Note represents a note belonging to a user.
@@ -22,6 +27,36 @@ type Note struct {
 	Message string `json:"message"`
 }
 
+// NoteCreateRequest is the request for NoteCreate.
+// swagger:parameters NoteCreate
+type NoteCreateRequest struct {
+	// in: body
+	Body struct {
+		// example: This is a note.
+		// required: true
+		Message string `json:"message"`
+	} `in:"body"`
+}
+
+// NoteCreateData contains the newly created note ID.
+type NoteCreateData struct {
+	// RecordID contains the newly created note ID.
+	// example: 314445cd-e9fb-4c58-58b6-777ee06465f5
+	// required: true
+	RecordID string `json:"record_id"`
+}
+
+// NoteCreateResponse returns a note ID.
+// swagger:response NoteCreateResponse
+type NoteCreateResponse struct {
+	// in: body
+	Body struct {
+		octane.CreatedStatusFields
+		// required: true
+		Data NoteCreateData `json:"data"`
+	}
+}
+
 // NoteCreate -
 // swagger:route POST /api/v1/note note NoteCreate
 //
@@ -34,63 +69,58 @@ type Note struct {
 //   201: NoteCreateResponse
 //   400: BadRequestResponse
 //   401: UnauthorizedResponse
+//   422: ValidationErrorResponse
 //   500: InternalServerErrorResponse
-func NoteCreate(c *app.Context) (err error) {
-	// swagger:parameters NoteCreate
-	type Request struct {
-		// in: body
-		Body struct {
-			// example: This is a note.
-			// required: true
-			Message string `json:"message"`
+func NoteCreate(ac *app.Context) echo.HandlerFunc {
+	return octane.Handle(http.StatusCreated, func(ctx context.Context, req *NoteCreateRequest) (*NoteCreateData, error) {
+		// Get the user ID.
+		userID, ok := app.UserID(ctx)
+		if !ok {
+			return nil, octane.NewHTTPError(http.StatusInternalServerError, "invalid user")
 		}
-	}
 
-	// Request validation.
-	req := new(Request)
-	if err = c.Bind(req); err != nil {
-		return c.BadRequestResponse(err.Error())
-	}
+		// Create the note.
+		ID, err := store.NoteCreate(ac.DB, userID, req.Body.Message)
+		if err != nil {
+			return nil, octane.InternalError(err)
+		}
 
-	// Get the user ID.
-	userID, ok := c.UserID()
-	if !ok {
-		return c.InternalServerErrorResponse("invalid user")
-	}
+		return &NoteCreateData{RecordID: ID}, nil
+	})
+}
 
-	// Create the note.
-	ID, err := store.NoteCreate(c.DB, userID, req.Body.Message)
-	if err != nil {
-		return c.InternalServerErrorResponse(err.Error())
-	}
+// NoteIndexRequest is the request for NoteIndex.
+// swagger:parameters NoteIndex
+type NoteIndexRequest struct {
+	octane.PageRequest
+}
 
-	// NoteCreateReponse returns a user ID.
-	// swagger:response NoteCreateResponse
-	type NoteCreateReponse struct {
-		// in: body
-		Body struct {
-			octane.CreatedStatusFields
+// NoteIndexResponse returns a page of notes.
+// swagger:response NoteIndexResponse
+type NoteIndexResponse struct {
+	// in: body
+	Body struct {
+		octane.OKStatusFields
+		// required: true
+		Data struct {
 			// required: true
-			Data struct {
-				// RecordID contains the newly created note ID.
-				// example: 314445cd-e9fb-4c58-58b6-777ee06465f5
-				// required: true
-				RecordID string `json:"record_id"`
-			} `json:"data"`
-		}
+			Notes []Note `json:"notes"`
+		} `json:"data"`
+		// required: true
+		Meta octane.PageMeta `json:"meta"`
 	}
-
-	// Set the note ID.
-	data := new(NoteCreateReponse).Body.Data
-	data.RecordID = ID
-
-	return c.DataResponse(http.StatusCreated, data)
 }
 
 // NoteIndex -
 // swagger:route GET /api/v1/note note NoteIndex
 //
-// Return all notes for the current user.
+// Return a page of notes for the current user.
+//
+// Produces:
+//   - application/json
+//   - application/xml
+//   - application/msgpack
+//   - text/csv
 //
 // Security:
 //   token:
@@ -99,19 +129,30 @@ func NoteCreate(c *app.Context) (err error) {
 //   200: NoteIndexResponse
 //   400: BadRequestResponse
 //   401: UnauthorizedResponse
+//   422: ValidationErrorResponse
 //   500: InternalServerErrorResponse
 func NoteIndex(c *app.Context) (err error) {
+	// Request validation.
+	req := new(NoteIndexRequest)
+	if err = c.Bind(req); err != nil {
+		return c.BindErrorResponse(err)
+	}
+
 	// Get the user ID.
 	userID, ok := c.UserID()
 	if !ok {
-		return c.InternalServerErrorResponse("invalid user")
+		return octane.NewHTTPError(http.StatusInternalServerError, "invalid user")
 	}
 
-	// Get a list of notes for the user.
+	// Get a page of notes for the user.
 	group := make([]store.Note, 0)
-	_, err = store.NoteFindAllByUser(c.DB, &group, userID)
+	meta, err := store.NoteFindPageByUser(c.DB, &group, userID, req.PageRequest)
 	if err != nil {
-		return c.InternalServerErrorResponse(err.Error())
+		var ve *octane.ValidationError
+		if errors.As(err, &ve) {
+			return c.BindErrorResponse(err)
+		}
+		return octane.InternalError(err)
 	}
 
 	// Copy the items to the JSON model.
@@ -120,36 +161,45 @@ func NoteIndex(c *app.Context) (err error) {
 		item := new(Note)
 		err = structcopy.ByTag(&u, "db", item, "json")
 		if err != nil {
-			return c.InternalServerErrorResponse(err.Error())
+			return octane.InternalError(err)
 		}
 		arr = append(arr, *item)
 	}
 
-	// NoteIndexResponse returns an array of notes.
-	// swagger:response NoteIndexResponse
-	type NoteIndexResponse struct {
-		// in: body
-		Body struct {
-			octane.OKStatusFields
-			// required: true
-			Data struct {
-				// required: true
-				Notes []Note `json:"notes"`
-			} `json:"data"`
-		}
-	}
-
 	// Set the notes.
 	data := new(NoteIndexResponse).Body.Data
 	data.Notes = arr
 
-	return c.DataResponse(http.StatusOK, data)
+	return c.PageResponse(http.StatusOK, data, meta)
+}
+
+// NoteShowRequest is the request for NoteShow.
+// swagger:parameters NoteShow
+type NoteShowRequest struct {
+	// example: 314445cd-e9fb-4c58-58b6-777ee06465f5
+	// in: path
+	NoteID string `json:"note_id" in:"path" validate:"required"`
+}
+
+// NoteShowResponse returns 200.
+// swagger:response NoteShowResponse
+type NoteShowResponse struct {
+	// in: body
+	Body struct {
+		octane.OKStatusFields
+		// required: true
+		Data struct {
+			// required: true
+			Note Note `json:"note"`
+		} `json:"data"`
+	}
 }
 
 // NoteShow -
 // swagger:route GET /api/v1/note/{note_id} note NoteShow
 //
-// Return a note for the current user.
+// Return a note for the current user. The note isn't sent again if it
+// matches the ETag in If-None-Match.
 //
 // Security:
 //   token:
@@ -158,68 +208,63 @@ func NoteIndex(c *app.Context) (err error) {
 //   200: NoteShowResponse
 //   400: BadRequestResponse
 //   401: UnauthorizedResponse
+//   422: ValidationErrorResponse
 //   500: InternalServerErrorResponse
 func NoteShow(c *app.Context) (err error) {
-	// swagger:parameters NoteShow
-	type Request struct {
-		// example: 314445cd-e9fb-4c58-58b6-777ee06465f5
-		// in: path
-		NoteID string `json:"note_id" validate:"required"`
-	}
-
 	// Request validation.
-	req := new(Request)
+	req := new(NoteShowRequest)
 	if err = c.Bind(req); err != nil {
-		return c.BadRequestResponse(err.Error())
+		return c.BindErrorResponse(err)
 	}
 
 	// Get the user ID.
 	userID, ok := c.UserID()
 	if !ok {
-		return c.InternalServerErrorResponse("invalid user")
+		return octane.NewHTTPError(http.StatusInternalServerError, "invalid user")
 	}
 
 	// Get the note for the user.
 	note := new(store.Note)
 	exists, err := store.FindOneByIDAndUser(c.DB, note, req.NoteID, userID)
 	if err != nil {
-		return c.InternalServerErrorResponse(err.Error())
+		return octane.InternalError(err)
 	} else if !exists {
 		return c.BadRequestResponse("invalid note")
 	}
 
-	// Copy the items to the JSON model.
-	item := new(Note)
-	err = structcopy.ByTag(&note, "db", item, "json")
+	// Create the response.
+	data, err := noteShowData(note)
 	if err != nil {
-		return c.InternalServerErrorResponse(err.Error())
+		return octane.InternalError(err)
 	}
 
-	// NoteShowResponse returns 200.
-	// swagger:response NoteShowResponse
-	type NoteShowResponse struct {
-		// in: body
-		Body struct {
-			octane.OKStatusFields
-			// required: true
-			Data struct {
-				// required: true
-				Note Note `json:"note"`
-			} `json:"data"`
-		}
+	// Send 304 if the client has the note in the same format.
+	if ok, err := c.VersionPreconditions(noteVersion(note), noteModified(note)); !ok {
+		return err
 	}
 
-	// Create the response.
-	data := new(NoteShowResponse).Body.Data
-	data.Note = *item
-
 	return c.DataResponse(http.StatusOK, data)
 }
 
+// NoteUpdateRequest is the request for NoteUpdate.
+// swagger:parameters NoteUpdate
+type NoteUpdateRequest struct {
+	// in: path
+	// example: 314445cd-e9fb-4c58-58b6-777ee06465f5
+	NoteID string `json:"note_id" in:"path" validate:"required"`
+	// in: body
+	Body struct {
+		// example: This is a note.
+		Message string `json:"message"`
+	} `in:"body"`
+}
+
 // NoteUpdate -
 // swagger:route PUT /api/v1/note/{note_id} note NoteUpdate
 //
-// Update a note for the current user.
+// Update a note for the current user. Send the ETag of the note from NoteShow
+// in If-Match to only update the note if it wasn't changed by another
+// request.
 //
 // Security:
 //   token:
@@ -228,54 +273,67 @@ func NoteShow(c *app.Context) (err error) {
 //   200: OKResponse
 //   400: BadRequestResponse
 //   401: UnauthorizedResponse
+//   412: PreconditionFailedResponse
+//   422: ValidationErrorResponse
 //   500: InternalServerErrorResponse
 func NoteUpdate(c *app.Context) (err error) {
-	// swagger:parameters NoteUpdate
-	type Request struct {
-		// in: path
-		// example: 314445cd-e9fb-4c58-58b6-777ee06465f5
-		NoteID string `json:"note_id" validate:"required"`
-		// in: body
-		Body struct {
-			// example: This is a note.
-			Message string `json:"message"`
-		}
-	}
-
 	// Request validation.
-	req := new(Request)
+	req := new(NoteUpdateRequest)
 	if err = c.Bind(req); err != nil {
-		return c.BadRequestResponse(err.Error())
+		return c.BindErrorResponse(err)
 	}
 
 	// Get the user ID.
 	userID, ok := c.UserID()
 	if !ok {
-		return c.InternalServerErrorResponse("invalid user")
+		return octane.NewHTTPError(http.StatusInternalServerError, "invalid user")
 	}
 
 	// Determine if the note exists for the user.
 	note := new(store.Note)
 	exists, err := store.FindOneByIDAndUser(c.DB, note, req.NoteID, userID)
 	if err != nil {
-		return c.InternalServerErrorResponse(err.Error())
+		return octane.InternalError(err)
 	} else if !exists {
 		return c.BadRequestResponse("invalid note")
 	}
 
-	// Update the note.
-	_, err = store.NoteUpdate(c.DB, req.NoteID, userID, req.Body.Message)
+	// Prevent a lost update if the note was changed by another request.
+	if ok, err := c.VersionPreconditions(noteVersion(note), noteModified(note)); !ok {
+		return err
+	}
+
+	// Update the note only if it still has the version the preconditions
+	// were checked against.
+	affected, err := store.NoteUpdateIfUnchanged(c.DB, req.NoteID, userID, note.Version, req.Body.Message)
 	if err != nil {
-		return c.InternalServerErrorResponse(err.Error())
+		return octane.InternalError(err)
+	} else if affected == 0 {
+		return octane.NewHTTPError(http.StatusPreconditionFailed, "the resource was changed by another request")
 	}
 
+	// Send the ETag of the updated note.
+	note.Version++
+	c.Response().Header().Set("ETag", c.VersionETag(noteVersion(note)))
+	c.Response().Header().Del(echo.HeaderLastModified)
+
 	return c.OKResponse("note updated")
 }
 
+// NoteDestroyRequest is the request for NoteDestroy.
+// swagger:parameters NoteDestroy
+type NoteDestroyRequest struct {
+	// in: path
+	// example: 314445cd-e9fb-4c58-58b6-777ee06465f5
+	NoteID string `json:"note_id" in:"path" validate:"required"`
+}
+
 // NoteDestroy -
 // swagger:route DELETE /api/v1/note/{note_id} note NoteDestroy
 //
-// Delete a note for the current user.
+// Delete a note for the current user. Send the ETag of the note from NoteShow
+// in If-Match to only delete the note if it wasn't changed by another
+// request.
 //
 // Security:
 //   token:
@@ -284,34 +342,76 @@ func NoteUpdate(c *app.Context) (err error) {
 //   200: OKResponse
 //   400: BadRequestResponse
 //   401: UnauthorizedResponse
+//   412: PreconditionFailedResponse
+//   422: ValidationErrorResponse
 //   500: InternalServerErrorResponse
 func NoteDestroy(c *app.Context) (err error) {
-	// swagger:parameters NoteDestroy
-	type Request struct {
-		// in: path
-		// example: 314445cd-e9fb-4c58-58b6-777ee06465f5
-		NoteID string `json:"note_id" validate:"required"`
-	}
-
 	// Request validation.
-	req := new(Request)
+	req := new(NoteDestroyRequest)
 	if err = c.Bind(req); err != nil {
-		return c.BadRequestResponse(err.Error())
+		return c.BindErrorResponse(err)
 	}
 
 	// Get the user ID.
 	userID, ok := c.UserID()
 	if !ok {
-		return c.InternalServerErrorResponse("invalid user")
+		return octane.NewHTTPError(http.StatusInternalServerError, "invalid user")
 	}
 
-	// Delete the note for the user.
-	affected, err := store.DeleteOneByIDAndUser(c.DB, new(store.Note), req.NoteID, userID)
+	// Determine if the note exists for the user.
+	note := new(store.Note)
+	exists, err := store.FindOneByIDAndUser(c.DB, note, req.NoteID, userID)
 	if err != nil {
-		return c.InternalServerErrorResponse(err.Error())
-	} else if affected == 0 {
+		return octane.InternalError(err)
+	} else if !exists {
 		return c.BadRequestResponse("note does not exist")
 	}
 
+	// Prevent deleting a note that was changed by another request.
+	if ok, err := c.VersionPreconditions(noteVersion(note), noteModified(note)); !ok {
+		return err
+	}
+
+	// Delete the note for the user only if it still has the version the
+	// preconditions were checked against.
+	affected, err := store.NoteDeleteIfUnchanged(c.DB, req.NoteID, userID, note.Version)
+	if err != nil {
+		return octane.InternalError(err)
+	} else if affected == 0 {
+		return octane.NewHTTPError(http.StatusPreconditionFailed, "the resource was changed by another request")
+	}
+
 	return c.OKResponse("note deleted")
 }
+
+// noteShowData returns the data of NoteShow for a note.
+func noteShowData(note *store.Note) (interface{}, error) {
+	// Copy the items to the JSON model.
+	item := new(Note)
+	err := structcopy.ByTag(note, "db", item, "json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := new(NoteShowResponse).Body.Data
+	data.Note = *item
+
+	return data, nil
+}
+
+// noteVersion returns the version of a note for the ETag. The version changes
+// on every write so the ETag changes even if the message is changed back.
+func noteVersion(note *store.Note) string {
+	return strconv.Itoa(note.Version)
+}
+
+// noteModified returns the time the note was last changed.
+func noteModified(note *store.Note) time.Time {
+	if note.UpdatedAt != nil {
+		return *note.UpdatedAt
+	} else if note.CreatedAt != nil {
+		return *note.CreatedAt
+	}
+
+	return time.Time{}
+}
@@ -109,25 +109,3 @@ func (d *DBW) SuppressNoRowsError(err error) error {
 	}
 	return err
 }
-
-/*
-// PaginatedResults returns the paginated results of a query.
-func (d *DBW) PaginatedResults(i interface{}, fn func() (interface{}, int,
-	error)) (int, error) {
-	v := reflect.ValueOf(i)
-	if v.Kind() != reflect.Ptr {
-		return 0, errors.New("must pass a pointer, not a value")
-	}
-
-	results, d2, d3 := fn()
-	if results == nil {
-		return d2, d3
-	}
-
-	arrPtr := reflect.ValueOf(i)
-	value := arrPtr.Elem()
-	itemPtr := reflect.ValueOf(results)
-	value.Set(itemPtr)
-
-	return d2, d3
-}*/
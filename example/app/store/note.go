@@ -3,6 +3,7 @@ package store
 import (
 	"time"
 
+	"github.com/josephspurrier/octane"
 	"github.com/josephspurrier/octane/example/app"
 	"github.com/josephspurrier/octane/example/app/lib/securegen"
 )
@@ -12,6 +13,7 @@ type Note struct {
 	ID        string     `db:"id"`
 	UserID    string     `db:"user_id"`
 	Message   string     `db:"message"`
+	Version   int        `db:"version"`
 	CreatedAt *time.Time `db:"created_at"`
 	UpdatedAt *time.Time `db:"updated_at"`
 	DeletedAt *time.Time `db:"deleted_at"`
@@ -50,7 +52,8 @@ func NoteUpdate(db app.IDatabase, ID, userID, message string) (affected int, err
 	result, err := db.Exec(`
 		UPDATE note
 		SET
-			message = ?
+			message = ?,
+			version = version + 1
 		WHERE id = ?
 		AND user_id = ?
 		LIMIT 1
@@ -59,6 +62,38 @@ func NoteUpdate(db app.IDatabase, ID, userID, message string) (affected int, err
 	return db.AffectedRows(result), err
 }
 
+// NoteUpdateIfUnchanged makes changes to an item only if it still has the
+// version. Each change increments the version so no rows are affected if the
+// item was changed by another request.
+func NoteUpdateIfUnchanged(db app.IDatabase, ID, userID string, version int, message string) (affected int, err error) {
+	result, err := db.Exec(`
+		UPDATE note
+		SET
+			message = ?,
+			version = version + 1
+		WHERE id = ?
+		AND user_id = ?
+		AND version = ?
+		LIMIT 1
+		`,
+		message, ID, userID, version)
+	return db.AffectedRows(result), err
+}
+
+// NoteDeleteIfUnchanged removes an item only if it still has the version. No
+// rows are affected if the item was changed by another request.
+func NoteDeleteIfUnchanged(db app.IDatabase, ID, userID string, version int) (affected int, err error) {
+	result, err := db.Exec(`
+		DELETE FROM note
+		WHERE id = ?
+		AND user_id = ?
+		AND version = ?
+		LIMIT 1
+		`,
+		ID, userID, version)
+	return db.AffectedRows(result), err
+}
+
 // NoteFindAllByUser returns all notes for a user.
 func NoteFindAllByUser(db app.IDatabase, dest *[]Note, userID string) (
 	total int, err error) {
@@ -71,3 +106,11 @@ func NoteFindAllByUser(db app.IDatabase, dest *[]Note, userID string) (
 		userID)
 	return len(*dest), db.SuppressNoRowsError(err)
 }
+
+// NoteFindPageByUser returns a page of notes for a user from the oldest to
+// the newest. The notes are sorted by the columns that can't contain user
+// data since the values are sent to the client in the cursors.
+func NoteFindPageByUser(db app.IDatabase, dest *[]Note, userID string,
+	page octane.PageRequest) (octane.PageMeta, error) {
+	return FindPageByUser(db, dest, new(Note), userID, page, "created_at", "id")
+}
@@ -3,6 +3,7 @@ package store_test
 import (
 	"testing"
 
+	"github.com/josephspurrier/octane"
 	"github.com/josephspurrier/octane/example/app/lib/testutil"
 	"github.com/josephspurrier/octane/example/app/store"
 	"github.com/labstack/echo/v4"
@@ -40,8 +41,105 @@ func TestNote(t *testing.T) {
 	assert.Equal(t, true, exists)
 	assert.Equal(t, "note2", n.Message)
 
+	// Each change increments the version.
+	assert.Equal(t, 2, n.Version)
+
+	// Only update the note if it still has the version, even if the message
+	// was changed back.
+	affected, err = store.NoteUpdateIfUnchanged(db, noteID, userID, 1, "note1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, affected)
+	affected, err = store.NoteUpdateIfUnchanged(db, noteID, userID, 2, "note1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, affected)
+	affected, err = store.NoteUpdateIfUnchanged(db, noteID, userID, 1, "note3")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, affected)
+
+	// Only delete the note if it still has the version.
+	affected, err = store.NoteDeleteIfUnchanged(db, noteID, userID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, affected)
+
 	// Delete the node.
 	affected, err = store.DeleteOneByIDAndUser(db, new(store.Note), noteID, userID)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, affected)
 }
+
+func TestNotePage(t *testing.T) {
+	e := echo.New()
+	db := testutil.LoadDatabase(e.Logger)
+	defer testutil.TeardownDatabase(db)
+
+	// Create a user with five notes.
+	userID, err := store.CreateUser(db, "first", "last", "email", "password")
+	assert.NoError(t, err)
+	for _, message := range []string{"c", "a", "e", "b", "d"} {
+		_, err = store.NoteCreate(db, userID, message)
+		assert.NoError(t, err)
+	}
+
+	messages := func(notes []store.Note) []string {
+		arr := make([]string, 0)
+		for _, n := range notes {
+			arr = append(arr, n.Message)
+		}
+		return arr
+	}
+
+	// Read every note to get the order. The notes are sorted by the time
+	// they were created and then by the ID.
+	all := make([]store.Note, 0)
+	_, err = store.NoteFindPageByUser(db, &all, userID, octane.PageRequest{Limit: 5})
+	assert.NoError(t, err)
+	assert.Len(t, all, 5)
+	order := messages(all)
+
+	// Read the first page.
+	notes := make([]store.Note, 0)
+	meta, err := store.NoteFindPageByUser(db, &notes, userID, octane.PageRequest{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, order[0:2], messages(notes))
+	assert.Equal(t, 5, meta.Total)
+	assert.Empty(t, meta.PrevCursor)
+
+	// The cursor only contains the time and the ID of the note.
+	cur, err := octane.ParseCursor(meta.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{notes[1].CreatedAt.UTC().Format("2006-01-02 15:04:05.999999"), notes[1].ID}, cur.Values)
+
+	// Read the next page.
+	notes = make([]store.Note, 0)
+	meta, err = store.NoteFindPageByUser(db, &notes, userID, octane.PageRequest{Limit: 2, Cursor: meta.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, order[2:4], messages(notes))
+
+	// Read the last page.
+	next := meta.NextCursor
+	notes = make([]store.Note, 0)
+	meta, err = store.NoteFindPageByUser(db, &notes, userID, octane.PageRequest{Limit: 2, Cursor: next})
+	assert.NoError(t, err)
+	assert.Equal(t, order[4:], messages(notes))
+	assert.Empty(t, meta.NextCursor)
+
+	// Go back a page.
+	notes = make([]store.Note, 0)
+	meta, err = store.NoteFindPageByUser(db, &notes, userID, octane.PageRequest{Limit: 2, Cursor: meta.PrevCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, order[2:4], messages(notes))
+	assert.NotEmpty(t, meta.PrevCursor)
+	assert.NotEmpty(t, meta.NextCursor)
+
+	// Read a page at an offset.
+	notes = make([]store.Note, 0)
+	meta, err = store.NoteFindPageByUser(db, &notes, userID, octane.PageRequest{Limit: 2, Offset: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, order[3:], messages(notes))
+	assert.Equal(t, 3, meta.Offset)
+	assert.Empty(t, meta.NextCursor)
+
+	// An invalid cursor fails validation.
+	_, err = store.NoteFindPageByUser(db, &notes, userID, octane.PageRequest{Cursor: "abc"})
+	assert.IsType(t, &octane.ValidationError{}, err)
+}
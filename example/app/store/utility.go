@@ -1,8 +1,13 @@
 package store
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
+	"github.com/josephspurrier/octane"
 	"github.com/josephspurrier/octane/example/app"
 )
 
@@ -58,6 +63,123 @@ func FindOneByIDAndUser(db app.IDatabase, dest app.IRecord, ID string, userID st
 	return db.RecordExists(err)
 }
 
+// *****************************************************************************
+// Page
+// *****************************************************************************
+
+// FindPageByUser returns a page of the items for a user. The dest must be a
+// pointer to a slice of structs with db tags. The items are sorted by the
+// columns in orderBy and the last column must be unique, like the primary
+// key. A page at a cursor uses keyset pagination so items are not skipped or
+// repeated when items are added or removed between pages.
+func FindPageByUser(db app.IDatabase, dest interface{}, record app.IRecord, userID string,
+	page octane.PageRequest, orderBy ...string) (meta octane.PageMeta, err error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return meta, errors.New("must pass a pointer to a slice")
+	}
+
+	cursor, err := page.PageCursor(len(orderBy))
+	if err != nil {
+		return meta, err
+	}
+
+	meta.Limit = page.PageLimit()
+	if cursor.IsZero() {
+		meta.Offset = page.Offset
+	}
+
+	err = db.QueryRowScan(&meta.Total, fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE user_id = ?
+		`, record.Table()), userID)
+	if err != nil {
+		return meta, err
+	}
+
+	// Read one more item than the limit to know if there is another page.
+	dir, cmp := "ASC", ">"
+	if cursor.Before {
+		dir, cmp = "DESC", "<"
+	}
+	where := "user_id = ?"
+	args := []interface{}{userID}
+	if !cursor.IsZero() {
+		where += fmt.Sprintf(" AND (%s) %s (%s)", strings.Join(orderBy, ", "), cmp,
+			strings.TrimSuffix(strings.Repeat("?,", len(orderBy)), ","))
+		args = append(args, cursor.Values...)
+	}
+	order := make([]string, 0, len(orderBy))
+	for _, col := range orderBy {
+		order = append(order, col+" "+dir)
+	}
+
+	err = db.Select(dest, fmt.Sprintf(`
+		SELECT *
+		FROM %s
+		WHERE %s
+		ORDER BY %s
+		LIMIT %d OFFSET %d
+		`, record.Table(), where, strings.Join(order, ", "), meta.Limit+1, meta.Offset),
+		args...)
+	if err = db.SuppressNoRowsError(err); err != nil {
+		return meta, err
+	}
+
+	rows := v.Elem()
+	more := rows.Len() > meta.Limit
+	if more {
+		rows.Set(rows.Slice(0, meta.Limit))
+	}
+	if cursor.Before {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	if rows.Len() == 0 {
+		return meta, nil
+	}
+
+	// A page before a cursor always has the item of the cursor after it.
+	if more || cursor.Before {
+		meta.NextCursor = octane.Cursor{Values: keyValues(rows.Index(rows.Len()-1), orderBy)}.String()
+	}
+	if cursor.Before && more || !cursor.Before && (!cursor.IsZero() || meta.Offset > 0) {
+		meta.PrevCursor = octane.Cursor{Values: keyValues(rows.Index(0), orderBy), Before: true}.String()
+	}
+
+	return meta, nil
+}
+
+// keyValues returns the values of the fields with the db tags of the columns.
+// A time is returned in the format of a MySQL DATETIME so it can be compared
+// with the column when it comes back in a cursor.
+func keyValues(row reflect.Value, columns []string) []interface{} {
+	row = reflect.Indirect(row)
+	values := make([]interface{}, 0, len(columns))
+	for _, col := range columns {
+		var val interface{}
+		for j := 0; j < row.NumField(); j++ {
+			if row.Type().Field(j).Tag.Get("db") != col {
+				continue
+			}
+			if f := reflect.Indirect(row.Field(j)); f.IsValid() {
+				val = f.Interface()
+			}
+			if t, ok := val.(time.Time); ok {
+				val = t.UTC().Format("2006-01-02 15:04:05.999999")
+			}
+			break
+		}
+		values = append(values, val)
+	}
+
+	return values
+}
+
 // *****************************************************************************
 // Delete
 // *****************************************************************************
@@ -0,0 +1,145 @@
+package octane
+
+import (
+	"reflect"
+	"strings"
+)
+
+// These are the locations supported by the in tag. They match the go-swagger
+// in annotations.
+const (
+	// InPath is a URL parameter from the router.
+	InPath = "path"
+	// InQuery is a query string value.
+	InQuery = "query"
+	// InHeader is a request header.
+	InHeader = "header"
+	// InCookie is a request cookie.
+	InCookie = "cookie"
+	// InBody receives the entire request body.
+	InBody = "body"
+	// InFormData is a member of the request body.
+	InFormData = "formData"
+)
+
+// bindField contains the binding information for a struct field.
+type bindField struct {
+	name  string
+	in    string
+	index int
+}
+
+// isForm returns true if the field is filled from the members of the body.
+func (f bindField) isForm() bool {
+	return f.in == "" || f.in == InFormData
+}
+
+// bindFields is a list of the fields of a struct.
+type bindFields []bindField
+
+// structFields returns the binding information for the fields of a struct
+// type. The name of the field is the json tag name.
+func structFields(t reflect.Type) bindFields {
+	fields := make(bindFields, 0, t.NumField())
+	for j := 0; j < t.NumField(); j++ {
+		sf := t.Field(j)
+		if (sf.PkgPath != "" && !sf.Anonymous) || sf.Type == strictType {
+			continue
+		}
+
+		name := jsonName(sf)
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = sf.Name
+		}
+
+		fields = append(fields, bindField{
+			name:  name,
+			in:    sf.Tag.Get("in"),
+			index: j,
+		})
+	}
+
+	return fields
+}
+
+// embeddedStruct returns the type of an embedded struct without a json name.
+// The fields of the struct are promoted to the parent.
+func embeddedStruct(sf reflect.StructField) (reflect.Type, bool) {
+	if !sf.Anonymous || jsonName(sf) != "" || sf.Type.Kind() != reflect.Struct || sf.Type == strictType {
+		return nil, false
+	}
+
+	return sf.Type, true
+}
+
+// explicit returns true if any of the fields have an in tag.
+func (fields bindFields) explicit() bool {
+	for _, f := range fields {
+		if f.in != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// body returns the field that receives the entire request body. If none of
+// the fields have an in tag, a field named body is used.
+func (fields bindFields) body() (bindField, bool) {
+	explicit := fields.explicit()
+	for _, f := range fields {
+		if f.in == InBody || (!explicit && strings.EqualFold(f.name, InBody)) {
+			return f, true
+		}
+	}
+
+	return bindField{}, false
+}
+
+// find returns the field with the matching name.
+func (fields bindFields) find(name string) (bindField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+
+	return bindField{}, false
+}
+
+// param returns true if the name matches a top level field, or a field of an
+// embedded struct, that isn't the body field.
+func (fields bindFields) param(t reflect.Type, name string) bool {
+	body, hasBody := fields.body()
+	for _, f := range fields {
+		if et, ok := embeddedStruct(t.Field(f.index)); ok && f.in == "" {
+			if structFields(et).param(et, name) {
+				return true
+			}
+			continue
+		}
+
+		if f.name == name {
+			return !hasBody || f.index != body.index
+		}
+	}
+
+	return false
+}
+
+// formKeyName returns the top level name from a form key like "a.b" or
+// "a[0]".
+func formKeyName(key string) string {
+	if i := strings.IndexAny(key, ".["); i >= 0 {
+		return key[:i]
+	}
+
+	return key
+}
+
+// jsonName returns the name from the json tag of a struct field.
+func jsonName(sf reflect.StructField) string {
+	return strings.Split(sf.Tag.Get("json"), ",")[0]
+}
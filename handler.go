@@ -0,0 +1,108 @@
+package octane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandlerFunc is a handler that receives a bound and validated request and
+// returns the data for the response.
+type HandlerFunc[Req any, Resp any] func(ctx context.Context, req *Req) (*Resp, error)
+
+// Handle returns an echo.HandlerFunc that binds and validates the request
+// into a new Req, calls fn with the context of the request, and sends the
+// returned data in the response envelope with the status code. If fn
+// returns nil data, only the status code is sent. If fn returns an error,
+// it's sent using ErrorResponse.
+func Handle[Req any, Resp any](code int, fn HandlerFunc[Req, Resp]) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		rc := &ResponseJSON{Context: c}
+
+		req := new(Req)
+		if err := c.Bind(req); err != nil {
+			return rc.BindErrorResponse(err)
+		}
+
+		resp, err := fn(c.Request().Context(), req)
+		if err != nil {
+			return rc.ErrorResponse(err)
+		} else if resp == nil {
+			return c.NoContent(code)
+		}
+
+		return rc.DataResponse(code, resp)
+	}
+}
+
+// handlerStatus returns the status code for an error returned from a
+// handler and the message that is safe to send to the client. An HTTPError
+// only sends the public message and an error without a status code is an
+// internal server error so the message is not sent.
+func handlerStatus(err error) (int, string) {
+	var oe *HTTPError
+	var sc statusCoder
+	var he *echo.HTTPError
+	switch {
+	case errors.As(err, &oe):
+		return oe.StatusCode(), oe.PublicMessage()
+	case errors.As(err, &sc):
+		// Only the message of the error with the status code is sent, not
+		// the context of the errors that wrap it.
+		return sc.StatusCode(), sc.(error).Error()
+	case errors.As(err, &he):
+		if msg, ok := he.Message.(string); ok {
+			return he.Code, msg
+		}
+		return he.Code, http.StatusText(he.Code)
+	}
+
+	return http.StatusInternalServerError, "an unexpected error occurred"
+}
+
+// ErrorHandler returns an echo.HTTPErrorHandler that sends the errors that
+// handlers return with ErrorResponse so a handler can return an HTTPError
+// instead of sending the response. If the response was already sent, only
+// the error is logged. The error is logged with the method, the URI, the
+// request ID, and the status code so the cause of an HTTPError is only in
+// the log. A server error is logged as an error and any other error as info.
+func ErrorHandler() echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		res := c.Response()
+		if !res.Committed {
+			var werr error
+			if c.Request().Method == http.MethodHead {
+				code, _ := handlerStatus(err)
+				werr = c.NoContent(code)
+			} else if werr = (&ResponseJSON{Context: c}).ErrorResponse(err); werr == err {
+				werr = nil
+			}
+			if werr != nil {
+				logError(c, werr)
+			}
+		}
+
+		logError(c, err)
+	}
+}
+
+// logError logs the error with the request.
+func logError(c echo.Context, err error) {
+	req := c.Request()
+	res := c.Response()
+	id := req.Header.Get(echo.HeaderXRequestID)
+	if len(id) == 0 {
+		id = res.Header().Get(echo.HeaderXRequestID)
+	}
+
+	msg := fmt.Sprintf("method=%s uri=%s status=%d request_id=%s error=%q",
+		req.Method, req.RequestURI, res.Status, id, err.Error())
+	if res.Status >= 500 {
+		c.Logger().Error(msg)
+	} else {
+		c.Logger().Info(msg)
+	}
+}
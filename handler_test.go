@@ -0,0 +1,209 @@
+package octane_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type noteRequest struct {
+	// in: path
+	NoteID string `json:"note_id" in:"path"`
+	// in: body
+	Body struct {
+		Message string `json:"message" validate:"required"`
+	} `in:"body"`
+}
+
+type noteResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+func TestHandle(t *testing.T) {
+	e := echo.New()
+	e.Binder = octane.NewBinder()
+
+	e.PUT("/note/:note_id", octane.Handle(http.StatusOK, func(ctx context.Context, req *noteRequest) (*noteResponse, error) {
+		switch req.NoteID {
+		case "404":
+			return nil, echo.NewHTTPError(http.StatusNotFound, "note not found")
+		case "415":
+			return nil, &octane.UnsupportedMediaTypeError{MediaType: "text/plain"}
+		case "500":
+			return nil, errors.New("database is down")
+		case "204":
+			return nil, nil
+		}
+		return &noteResponse{ID: req.NoteID, Message: req.Body.Message}, nil
+	}))
+
+	tests := []struct {
+		path string
+		body string
+		code int
+		want string
+	}{
+		{"/note/10", `{"message":"hello"}`, http.StatusOK,
+			`{"data":{"id":"10","message":"hello"},"status_code":200,"status_message":"OK"}`},
+		{"/note/10", `{}`, http.StatusUnprocessableEntity,
+			`{"message":"the data submitted failed validation","status_code":422,"status_message":"Unprocessable Entity",` +
+				`"errors":[{"field":"message","rule":"required","message":"message is required"}]}`},
+		{"/note/10", `{"message":`, http.StatusBadRequest,
+			`{"message":"body is malformed at byte 11: unexpected EOF","status_code":400,"status_message":"Bad Request"}`},
+		{"/note/404", `{"message":"hello"}`, http.StatusNotFound,
+			`{"message":"note not found","status_code":404,"status_message":"Not Found"}`},
+		{"/note/415", `{"message":"hello"}`, http.StatusUnsupportedMediaType,
+			`{"message":"unsupported media type: text/plain","status_code":415,"status_message":"Unsupported Media Type"}`},
+		{"/note/500", `{"message":"hello"}`, http.StatusInternalServerError,
+			`{"message":"an unexpected error occurred","status_code":500,"status_message":"Internal Server Error"}`},
+		{"/note/204", `{"message":"hello"}`, http.StatusOK, ``},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("PUT", tt.path, strings.NewReader(tt.body))
+		r.Header.Add("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		assert.Equal(t, tt.code, w.Code, tt.path)
+		if len(tt.want) > 0 {
+			assert.JSONEq(t, tt.want, w.Body.String(), tt.path)
+		} else {
+			assert.Empty(t, w.Body.String(), tt.path)
+		}
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := &octane.ResponseJSON{Context: e.NewContext(req, rec)}
+
+	// The cause is returned so it can be logged but it's not sent.
+	err := errors.New("database is down")
+	assert.Equal(t, err, c.ErrorResponse(err))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "database")
+
+	// Only the message of a wrapped error with a status code is sent.
+	rec = httptest.NewRecorder()
+	c = &octane.ResponseJSON{Context: e.NewContext(req, rec)}
+	err = fmt.Errorf("note could not be read from db-1: %w", &octane.SyntaxError{Offset: 2, Err: errors.New("unexpected end of JSON input")})
+	assert.Equal(t, err, c.ErrorResponse(err))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{
+		"message": "body is malformed at byte 2: unexpected end of JSON input",
+		"status_code": 400,
+		"status_message": "Bad Request"
+	}`, rec.Body.String())
+}
+
+func TestHTTPError(t *testing.T) {
+	cause := errors.New("database is down")
+	err := octane.InternalError(cause)
+	assert.Equal(t, http.StatusInternalServerError, err.StatusCode())
+	assert.Equal(t, "an unexpected error occurred", err.PublicMessage())
+	assert.EqualError(t, err, "an unexpected error occurred: database is down")
+	assert.True(t, errors.Is(err, cause))
+
+	err = octane.NewHTTPError(http.StatusConflict, "")
+	assert.Equal(t, "Conflict", err.PublicMessage())
+	assert.Equal(t, http.StatusInternalServerError, octane.NewHTTPError(http.StatusOK, "ok").StatusCode())
+}
+
+// errorServer returns echo with the ErrorHandler, a route that returns the
+// error, and the log output.
+func errorServer(err error) (*echo.Echo, *strings.Builder) {
+	e := echo.New()
+	e.HTTPErrorHandler = octane.ErrorHandler()
+	logs := new(strings.Builder)
+	e.Logger.SetOutput(logs)
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set(echo.HeaderXRequestID, "abc")
+			return next(c)
+		}
+	})
+	e.GET("/note", func(c echo.Context) error {
+		return err
+	})
+	e.POST("/note", func(c echo.Context) error {
+		rc := &octane.ResponseJSON{Context: c}
+		return rc.BadRequestResponse("invalid note")
+	})
+
+	return e, logs
+}
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		want   string
+		logged bool
+	}{
+		{"internal error", octane.InternalError(errors.New("database is down")), http.StatusInternalServerError,
+			`{"message":"an unexpected error occurred","status_code":500,"status_message":"Internal Server Error"}`, true},
+		{"plain error", errors.New("database is down"), http.StatusInternalServerError,
+			`{"message":"an unexpected error occurred","status_code":500,"status_message":"Internal Server Error"}`, true},
+		{"details", octane.NewHTTPError(http.StatusConflict, "note is locked").
+			WithCause(errors.New("locked by user 10")).
+			WithDetails(map[string]string{"locked_by": "jsmith"}), http.StatusConflict,
+			`{"message":"note is locked","status_code":409,"status_message":"Conflict","details":{"locked_by":"jsmith"}}`, false},
+		{"echo error", echo.ErrForbidden, http.StatusForbidden,
+			`{"message":"Forbidden","status_code":403,"status_message":"Forbidden"}`, false},
+		{"validation", &octane.ValidationError{Errors: []octane.FieldError{{Field: "id", Rule: "required", Message: "id is required"}}},
+			http.StatusUnprocessableEntity, `{"message":"the data submitted failed validation","status_code":422,` +
+				`"status_message":"Unprocessable Entity","errors":[{"field":"id","rule":"required","message":"id is required"}]}`, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e, logs := errorServer(tc.err)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/note?id=1", nil))
+
+			assert.Equal(t, tc.code, rec.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(rec.Body.String()))
+			if tc.logged {
+				// The cause is only in the log.
+				assert.Contains(t, logs.String(), `"level":"ERROR"`)
+				assert.Contains(t, logs.String(), `method=GET uri=/note?id=1 status=500 request_id=abc`)
+				assert.Contains(t, logs.String(), `database is down`)
+			} else {
+				assert.Equal(t, "", logs.String())
+			}
+		})
+	}
+}
+
+func TestErrorHandlerCommitted(t *testing.T) {
+	// The response from the handler is sent once.
+	e, logs := errorServer(nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/note", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, `{"message":"invalid note","status_code":400,"status_message":"Bad Request"}`,
+		strings.TrimSpace(rec.Body.String()))
+	assert.Equal(t, "", logs.String())
+
+	// A HEAD request doesn't have a body.
+	e, _ = errorServer(nil)
+	e.HEAD("/note", func(c echo.Context) error {
+		return octane.NewHTTPError(http.StatusNotFound, "note not found")
+	})
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/note", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, 0, rec.Body.Len())
+}
@@ -0,0 +1,131 @@
+package octane
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseAccept returns the values of an Accept style header ordered by the
+// q-value from highest to lowest. Values with a q-value of 0 are removed and
+// values with the same q-value keep the order from the header.
+func parseAccept(header string) []string {
+	type item struct {
+		value string
+		q     float64
+	}
+
+	items := make([]item, 0)
+	for _, part := range strings.Split(header, ",") {
+		arr := strings.Split(part, ";")
+		value := strings.TrimSpace(arr[0])
+		if len(value) == 0 {
+			continue
+		}
+
+		if q := quality(arr[1:]); q > 0 {
+			items = append(items, item{value: value, q: q})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].q > items[j].q
+	})
+
+	values := make([]string, 0, len(items))
+	for _, i := range items {
+		values = append(values, i.value)
+	}
+
+	return values
+}
+
+// acceptQuality returns the q-value of the media type from the most specific
+// media range of the Accept header that matches it so an explicit q=0
+// excludes a media type that a wildcard accepts. A media type without a
+// match has a q-value of 0.
+func acceptQuality(header string, mediaType string) float64 {
+	best, q := -1, 0.0
+	for _, part := range strings.Split(header, ",") {
+		arr := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(arr[0]))
+
+		specificity := -1
+		switch {
+		case value == mediaType:
+			specificity = 2
+		case strings.HasSuffix(value, "/*") &&
+			strings.HasPrefix(mediaType, strings.TrimSuffix(value, "*")):
+			specificity = 1
+		case value == "*/*":
+			specificity = 0
+		}
+
+		if specificity > best {
+			best, q = specificity, quality(arr[1:])
+		}
+	}
+
+	return q
+}
+
+// quality returns the q-value from the parameters of a value in an Accept
+// style header. The q-value is 1 if it's missing or malformed.
+func quality(params []string) float64 {
+	q := 1.0
+	for _, param := range params {
+		param = strings.TrimSpace(param)
+		if strings.HasPrefix(param, "q=") {
+			if f, err := strconv.ParseFloat(param[2:], 64); err == nil {
+				q = f
+			}
+		}
+	}
+
+	return q
+}
+
+// headerValues returns the values of a header or a cookie in the format the
+// form decoder expects for the field type. The values of a slice are split on
+// commas and the values of a time are converted from an HTTP date to
+// RFC 3339.
+func headerValues(t reflect.Type, vals []string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// An HTTP date contains a comma so a slice of times is not split.
+	split := t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 &&
+		t.Elem() != timeType
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	values := make([]string, 0, len(vals))
+	for _, val := range vals {
+		parts := []string{val}
+		if split {
+			parts = strings.Split(val, ",")
+		}
+
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if len(part) == 0 {
+				continue
+			}
+
+			if t == timeType {
+				if tm, err := http.ParseTime(part); err == nil {
+					part = tm.Format(time.RFC3339)
+				}
+			}
+
+			values = append(values, part)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,62 @@
+// Package translation registers validation messages with a translator.
+package translation
+
+import (
+	"reflect"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Register adds the messages to the translator and registers each rule with
+// the validator. A message can use {0} for the field name and then {1} for
+// the rule parameter. For the rules that depend on the field type, a key like
+// "min-string", "min-items", or "min-number" is used before the rule name.
+func Register(v *validator.Validate, trans ut.Translator, messages map[string]string) (err error) {
+	tags := make(map[string]bool)
+	for key, text := range messages {
+		if err = trans.Add(key, text, false); err != nil {
+			return
+		}
+		tags[strings.Split(key, "-")[0]] = true
+	}
+
+	for tag := range tags {
+		err = v.RegisterTranslation(tag, trans, func(ut.Translator) error {
+			return nil
+		}, translate)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// translate returns the message for a failed field.
+func translate(trans ut.Translator, fe validator.FieldError) string {
+	msg, err := trans.T(fe.Tag()+"-"+kind(fe.Kind()), fe.Field(), fe.Param())
+	if err == nil {
+		return msg
+	}
+
+	msg, err = trans.T(fe.Tag(), fe.Field(), fe.Param())
+	if err != nil {
+		return fe.Error()
+	}
+
+	return msg
+}
+
+// kind returns the type of the field used to pick a message.
+func kind(k reflect.Kind) string {
+	switch k {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	}
+
+	return "number"
+}
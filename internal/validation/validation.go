@@ -0,0 +1,85 @@
+// Package validation converts the field errors from go-playground/validator
+// so the v9 and the v10 validators return the same messages.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+)
+
+// FieldError contains the methods shared by the field errors of the v9 and
+// the v10 validators.
+type FieldError interface {
+	Tag() string
+	Namespace() string
+	Field() string
+	Param() string
+	Kind() reflect.Kind
+	Translate(trans ut.Translator) string
+	Error() string
+}
+
+// Field returns the path to the field without the struct name.
+func Field(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return namespace
+}
+
+// Message returns a user friendly message for a failed field. If the
+// translator doesn't have a message for the rule, an English message is used.
+func Message(fe FieldError, trans ut.Translator) string {
+	if trans != nil {
+		if msg := fe.Translate(trans); msg != fe.Error() {
+			return msg
+		}
+	}
+
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", fe.Field())
+	case "url":
+		return fmt.Sprintf("%s must be a valid URL", fe.Field())
+	case "uuid", "uuid4":
+		return fmt.Sprintf("%s must be a valid UUID", fe.Field())
+	case "len":
+		return fmt.Sprintf("%s must be %s%s long", fe.Field(), fe.Param(), unit)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s%s", fe.Field(), fe.Param(), unit)
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s%s", fe.Field(), fe.Param(), unit)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s%s", fe.Field(), fe.Param(), unit)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s%s", fe.Field(), fe.Param(), unit)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", fe.Field(), fe.Param())
+	}
+
+	return fmt.Sprintf("%s failed on the '%s' rule", fe.Field(), fe.Tag())
+}
+
+// TagName returns the name from the json tag of a struct field so the field
+// errors use the same names as the request.
+func TagName(sf reflect.StructField) string {
+	if name := strings.Split(sf.Tag.Get("json"), ",")[0]; name != "-" {
+		return name
+	}
+
+	return ""
+}
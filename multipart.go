@@ -0,0 +1,81 @@
+package octane
+
+import (
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"sort"
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// defaultMaxMemory is the number of bytes of a multipart form stored in
+// memory. The rest is stored in temporary files.
+const defaultMaxMemory = 32 << 20
+
+// fileSizeError returns a ValidationError for each file that is larger than
+// the max file size or nil if all files are allowed. A max size of 0 allows
+// any size.
+func fileSizeError(files map[string][]*multipart.FileHeader, maxSize int64) error {
+	if maxSize <= 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ve := new(ValidationError)
+	for _, key := range keys {
+		for _, fh := range files[key] {
+			if fh.Size > maxSize {
+				ve.Errors = append(ve.Errors, FieldError{
+					Field:   key,
+					Rule:    "max_file_size",
+					Param:   fmt.Sprint(maxSize),
+					Message: fmt.Sprintf("%s must be at most %v bytes", key, maxSize),
+				})
+				break
+			}
+		}
+	}
+
+	if len(ve.Errors) == 0 {
+		return nil
+	}
+
+	return ve
+}
+
+// unmarshalFiles will set the uploaded files on the *multipart.FileHeader
+// and []*multipart.FileHeader fields of the body field. If there is no body
+// field, the files are set on the fields that are not parameters.
+func unmarshalFiles(elem reflect.Value, fields bindFields, files map[string][]*multipart.FileHeader) {
+	if f, ok := fields.body(); ok {
+		elem = reflect.Indirect(elem.Field(f.index))
+		if elem.Kind() != reflect.Struct {
+			return
+		}
+		fields = structFields(elem.Type())
+	}
+
+	for _, f := range fields {
+		arr, ok := files[f.name]
+		if !ok || !f.isForm() || len(arr) == 0 {
+			continue
+		}
+
+		field := elem.Field(f.index)
+		switch field.Type() {
+		case fileHeaderType:
+			field.Set(reflect.ValueOf(arr[0]))
+		case fileHeaderSliceType:
+			field.Set(reflect.ValueOf(arr))
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Package muxparam returns the URL parameters from the gorilla/mux router so
+// the octane Binder can be used with gorilla/mux.
+package muxparam
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/josephspurrier/octane"
+)
+
+// New returns an IRouter for the variables of the gorilla/mux route that
+// matched the request.
+func New(r *http.Request) octane.IRouter {
+	vars := mux.Vars(r)
+	return octane.ParamFunc(func(param string) string {
+		return vars[param]
+	})
+}
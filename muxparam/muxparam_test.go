@@ -0,0 +1,42 @@
+package muxparam_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/josephspurrier/octane"
+	"github.com/josephspurrier/octane/muxparam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindRequest(t *testing.T) {
+	type request struct {
+		// in: path
+		NoteID string `json:"note_id" in:"path" validate:"required"`
+		// in: body
+		Body struct {
+			Message string `json:"message" validate:"required"`
+		} `in:"body"`
+	}
+
+	cb := octane.NewBinder()
+	router := mux.NewRouter()
+
+	var req *request
+	var err error
+	router.HandleFunc("/note/{note_id}", func(w http.ResponseWriter, r *http.Request) {
+		req = new(request)
+		err = cb.BindRequest(req, r, muxparam.New(r))
+	}).Methods("PUT")
+
+	r := httptest.NewRequest("PUT", "/note/10", strings.NewReader(`{"message":"hello"}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", req.NoteID)
+	assert.Equal(t, "hello", req.Body.Message)
+}
@@ -0,0 +1,243 @@
+package octane
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MIMETextCSV is the media type of a CSV response.
+const MIMETextCSV = "text/csv"
+
+// responseEncoder encodes the data of a response for a media type.
+type responseEncoder struct {
+	mediaType string
+	// aliases are other media types that use the encoder.
+	aliases []string
+	// envelope is true if the data is sent with the status_code and the
+	// status_message.
+	envelope bool
+	encode   func(w io.Writer, v interface{}) error
+	// stream returns the writer for the items of a StreamResponse.
+	stream func(w io.Writer, code int) itemStream
+}
+
+// responseEncoders are the encoders in the order they are used when the
+// client accepts any media type.
+var responseEncoders = []responseEncoder{
+	{echo.MIMEApplicationJSON, nil, true, encodeJSON, nil},
+	{echo.MIMEApplicationXML, []string{echo.MIMETextXML}, true, encodeXML, nil},
+	{echo.MIMEApplicationMsgpack, []string{"application/x-msgpack", "application/vnd.msgpack"}, true, encodeMsgpack, nil},
+	{MIMETextCSV, nil, false, encodeCSV, nil},
+}
+
+// dataEnvelope contains the data of a response with the status code.
+type dataEnvelope struct {
+	Data          interface{} `json:"data"`
+	Meta          *PageMeta   `json:"meta,omitempty"`
+	StatusCode    int         `json:"status_code"`
+	StatusMessage string      `json:"status_message"`
+}
+
+// negotiate returns the media type and the encoder for the Accept header
+// using the q-values. The first encoder is used when the header is empty and
+// a media type with a q-value of 0 is never used.
+// False is returned if none of the media types are available.
+func negotiate(accept string, encoders []responseEncoder) (string, responseEncoder, bool) {
+	if len(strings.TrimSpace(accept)) == 0 {
+		return encoders[0].mediaType, encoders[0], true
+	}
+
+	for _, value := range parseAccept(accept) {
+		mt := strings.ToLower(value)
+		for _, enc := range encoders {
+			mediaType := ""
+			switch {
+			case mt == "*/*", mt == enc.mediaType:
+				mediaType = enc.mediaType
+			case strings.HasSuffix(mt, "/*") &&
+				strings.HasPrefix(enc.mediaType, strings.TrimSuffix(mt, "*")):
+				mediaType = enc.mediaType
+			}
+			for _, alias := range enc.aliases {
+				if mt == alias {
+					mediaType = alias
+				}
+			}
+
+			// A media type excluded with q=0 isn't sent for a wildcard.
+			if len(mediaType) > 0 && acceptQuality(accept, mediaType) > 0 {
+				return mediaType, enc, true
+			}
+		}
+	}
+
+	return "", responseEncoder{}, false
+}
+
+// notAcceptable returns the message for a 406 with the available media types.
+func notAcceptable(encoders []responseEncoder) string {
+	types := make([]string, 0, len(encoders))
+	for _, enc := range encoders {
+		types = append(types, enc.mediaType)
+	}
+
+	return fmt.Sprintf("the response is only available as %s", strings.Join(types, ", "))
+}
+
+// encodeJSON encodes the value as JSON.
+func encodeJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// encodeMsgpack encodes the value as MessagePack using the json tags.
+func encodeMsgpack(w io.Writer, v interface{}) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(v)
+}
+
+// encodeXML encodes the value as XML using the json tags for the element
+// names so the XML matches the JSON. The value is encoded as JSON first and
+// each item of an array is an item element. A key that isn't a valid XML
+// name, like the key of a map, is an entry element with a key attribute.
+func encodeXML(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err = jsonToXML(dec, enc, xmlElement("response")); err != nil {
+		return err
+	}
+
+	return enc.Flush()
+}
+
+// jsonToXML reads the next JSON value from the decoder and writes it as the
+// element.
+func jsonToXML(dec *json.Decoder, enc *xml.Encoder, start xml.StartElement) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if err = enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		for dec.More() {
+			child := xmlElement("item")
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = xmlElement(key.(string))
+			}
+			if err = jsonToXML(dec, enc, child); err != nil {
+				return err
+			}
+		}
+		// Read the closing delimiter.
+		if _, err = dec.Token(); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		if err = enc.EncodeToken(xml.CharData(fmt.Sprint(t))); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// xmlElement returns the element for a JSON key. A key that isn't a valid XML
+// name is the key attribute of an entry element.
+func xmlElement(key string) xml.StartElement {
+	if isXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+	}
+}
+
+// isXMLName returns true if the name starts with a letter or an underscore
+// and only contains letters, digits, underscores, hyphens, and periods. A
+// colon is left out since it separates a namespace prefix.
+func isXMLName(name string) bool {
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r), r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+
+	return len(name) > 0
+}
+
+// writeData encodes the data directly to the response using the encoder for
+// the Accept header. The meta of a page is added to the envelope. The
+// headers are set before the status code is written.
+func writeData(c echo.Context, code int, i interface{}, meta *PageMeta) (bool, error) {
+	res := c.Response()
+	res.Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+	mediaType, enc, ok := negotiate(c.Request().Header.Get(echo.HeaderAccept), responseEncoders)
+	if !ok {
+		return false, nil
+	}
+
+	setContentType(res, mediaType)
+	res.WriteHeader(code)
+
+	// The status code is already sent so an error can only be logged.
+	return true, enc.encode(res, dataValue(enc, code, i, meta))
+}
+
+// dataValue returns the value to encode for the data. The data is in an
+// envelope with the meta of a page and the status if the encoder uses one.
+func dataValue(enc responseEncoder, code int, i interface{}, meta *PageMeta) interface{} {
+	if !enc.envelope {
+		return i
+	}
+
+	return dataEnvelope{
+		Data:          i,
+		Meta:          meta,
+		StatusCode:    code,
+		StatusMessage: http.StatusText(code),
+	}
+}
+
+// setContentType sets the Content-Type header with the charset for a text
+// media type.
+func setContentType(res *echo.Response, mediaType string) {
+	if strings.HasPrefix(mediaType, "text/") || mediaType == echo.MIMEApplicationXML {
+		mediaType += "; charset=UTF-8"
+	}
+	res.Header().Set(echo.HeaderContentType, mediaType)
+}
@@ -0,0 +1,138 @@
+package octane_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type negotiateAuthor struct {
+	Email string `json:"email"`
+}
+
+type negotiateNote struct {
+	ID      string           `json:"id"`
+	Message string           `json:"message"`
+	Count   int              `json:"count"`
+	Created time.Time        `json:"created"`
+	Author  *negotiateAuthor `json:"author"`
+	Secret  string           `json:"-"`
+}
+
+type negotiateData struct {
+	Notes []negotiateNote `json:"notes"`
+}
+
+// negotiateResponse sends the data with the Accept header.
+func negotiateResponse(accept string, data interface{}) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if len(accept) > 0 {
+		req.Header.Set(echo.HeaderAccept, accept)
+	}
+	rec := httptest.NewRecorder()
+	c := &octane.ResponseJSON{Context: e.NewContext(req, rec)}
+	c.DataResponse(http.StatusOK, data)
+
+	return rec
+}
+
+func TestDataResponseNegotiate(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := negotiateData{Notes: []negotiateNote{
+		{ID: "1", Message: "hello, world", Count: 2, Created: created, Author: &negotiateAuthor{Email: "jsmith@example.com"}},
+		{ID: "2", Message: "no author", Secret: "hidden"},
+	}}
+
+	tests := []struct {
+		accept      string
+		contentType string
+		want        string
+	}{
+		{"", echo.MIMEApplicationJSON,
+			`{"data":{"notes":[` +
+				`{"id":"1","message":"hello, world","count":2,"created":"2024-01-02T03:04:05Z","author":{"email":"jsmith@example.com"}},` +
+				`{"id":"2","message":"no author","count":0,"created":"0001-01-01T00:00:00Z","author":null}]},` +
+				`"status_code":200,"status_message":"OK"}` + "\n"},
+		{"text/csv", "text/csv; charset=UTF-8",
+			"id,message,count,created,author.email\n" +
+				"1,\"hello, world\",2,2024-01-02T03:04:05Z,jsmith@example.com\n" +
+				"2,no author,0,0001-01-01T00:00:00Z,\n"},
+		{"application/json;q=0.5, text/xml", "text/xml; charset=UTF-8",
+			`<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<response><data><notes>` +
+				`<item><id>1</id><message>hello, world</message><count>2</count><created>2024-01-02T03:04:05Z</created><author><email>jsmith@example.com</email></author></item>` +
+				`<item><id>2</id><message>no author</message><count>0</count><created>0001-01-01T00:00:00Z</created><author></author></item>` +
+				`</notes></data><status_code>200</status_code><status_message>OK</status_message></response>`},
+		{"text/html, text/*;q=0.8", "text/csv; charset=UTF-8", ""},
+		{"text/html, */*;q=0.1", echo.MIMEApplicationJSON, ""},
+		{"application/json;q=0, */*", "application/xml; charset=UTF-8", ""},
+		{"*/*, application/*;q=0", "text/csv; charset=UTF-8", ""},
+		{"application/*;q=0, application/msgpack", echo.MIMEApplicationMsgpack, ""},
+	}
+
+	for _, tc := range tests {
+		rec := negotiateResponse(tc.accept, data)
+		assert.Equal(t, http.StatusOK, rec.Code, tc.accept)
+		assert.Equal(t, tc.contentType, rec.Result().Header.Get(echo.HeaderContentType), tc.accept)
+		assert.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary), tc.accept)
+		if len(tc.want) > 0 {
+			assert.Equal(t, tc.want, rec.Body.String(), tc.accept)
+		}
+	}
+}
+
+func TestDataResponseMsgpack(t *testing.T) {
+	rec := negotiateResponse(echo.MIMEApplicationMsgpack, negotiateNote{ID: "1", Message: "hello"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationMsgpack, rec.Header().Get(echo.HeaderContentType))
+
+	got := make(map[string]interface{})
+	assert.Nil(t, msgpack.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, "hello", got["data"].(map[string]interface{})["message"])
+	assert.EqualValues(t, 200, got["status_code"])
+}
+
+func TestDataResponseCSVValues(t *testing.T) {
+	rec := negotiateResponse("text/csv", []map[string]interface{}{
+		{"id": 1, "tags": []string{"a", "b"}},
+		{"id": 2, "name": "two"},
+	})
+	assert.Equal(t, "id,name,tags\n1,,\"[\"\"a\"\",\"\"b\"\"]\"\n2,two,\n", rec.Body.String())
+
+	rec = negotiateResponse("text/csv", []string{"a", "b"})
+	assert.Equal(t, "value\na\nb\n", rec.Body.String())
+
+	rec = negotiateResponse("text/csv", negotiateAuthor{Email: "jsmith@example.com"})
+	assert.Equal(t, "email\njsmith@example.com\n", rec.Body.String())
+}
+
+func TestDataResponseXMLNames(t *testing.T) {
+	rec := negotiateResponse(echo.MIMEApplicationXML, map[string]interface{}{
+		"1st": 1, "a b": "two", "x<y": true, "name": "ok",
+	})
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<response><data><entry key="1st">1</entry><entry key="a b">two</entry><name>ok</name>`+
+		`<entry key="x&lt;y">true</entry></data>`+
+		`<status_code>200</status_code><status_message>OK</status_message></response>`, rec.Body.String())
+}
+
+func TestDataResponseNotAcceptable(t *testing.T) {
+	rec := negotiateResponse("text/html, image/*", negotiateData{})
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+	assert.JSONEq(t, `{
+		"message": "the response is only available as application/json, application/xml, application/msgpack, text/csv",
+		"status_code": 406,
+		"status_message": "Not Acceptable"
+	}`, rec.Body.String())
+
+	// A media type excluded with q=0 isn't sent for a wildcard.
+	rec = negotiateResponse("application/*;q=0, text/*;q=0, */*", negotiateData{})
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+}
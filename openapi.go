@@ -0,0 +1,385 @@
+package octane
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIInfo contains the information about the API.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// SecurityScheme describes how a request is authorized.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Route describes an endpoint for the OpenAPI document.
+type Route struct {
+	// Method is the HTTP method like GET.
+	Method string
+	// Path uses the echo format like /api/v1/note/:note_id.
+	Path string
+	// OperationID is the unique name of the route.
+	OperationID string
+	// Summary is a short description of the route.
+	Summary string
+	// Description is a long description of the route.
+	Description string
+	// Tags are used to group the routes.
+	Tags []string
+	// Security contains the names of the security schemes for the route.
+	Security []string
+	// Request is the struct passed to Bind. The parameters and the body are
+	// read from the in tags.
+	Request interface{}
+	// Responses contains the response for each status code. A struct with a
+	// Body field, like OKResponse, uses the type of the Body field.
+	Responses map[int]interface{}
+}
+
+// OpenAPI builds an OpenAPI 3.1 document at runtime using the request and
+// response structs of each route. The json tags are used for the names, the
+// in tags for the location of the parameters, and the validate and default
+// tags for the constraints.
+type OpenAPI struct {
+	mu      sync.RWMutex
+	info    OpenAPIInfo
+	paths   map[string]map[string]*operation
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+	schemes map[string]SecurityScheme
+}
+
+// operation is an operation in the OpenAPI document.
+type operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]response   `json:"responses"`
+}
+
+// parameter is a path, query, header, or cookie parameter.
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// requestBody is the body of a request.
+type requestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+// response is a response for a status code.
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+// mediaType contains the schema for a media type.
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// NewOpenAPI returns an empty OpenAPI document.
+func NewOpenAPI(info OpenAPIInfo) *OpenAPI {
+	return &OpenAPI{
+		info:    info,
+		paths:   make(map[string]map[string]*operation),
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+		schemes: make(map[string]SecurityScheme),
+	}
+}
+
+// AddSecurityScheme adds a security scheme that can be used by the routes.
+func (o *OpenAPI) AddSecurityScheme(name string, scheme SecurityScheme) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.schemes[name] = scheme
+}
+
+// Add adds the route to echo and describes it in the document so the
+// document always matches the routes.
+func (o *OpenAPI) Add(e *echo.Echo, r Route, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	o.Describe(r)
+	return e.Add(r.Method, r.Path, h, m...)
+}
+
+// Describe adds the route to the document.
+func (o *OpenAPI) Describe(r Route) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	path, pathParams := openAPIPath(r.Path)
+	op := &operation{
+		OperationID: r.OperationID,
+		Summary:     r.Summary,
+		Description: r.Description,
+		Tags:        r.Tags,
+		Responses:   make(map[string]response),
+	}
+
+	for _, name := range r.Security {
+		op.Security = append(op.Security, map[string][]string{name: {}})
+	}
+
+	if r.Request != nil {
+		o.describeRequest(op, r.Method, reflect.TypeOf(r.Request), pathParams)
+	}
+
+	for code, resp := range r.Responses {
+		op.Responses[strconv.Itoa(code)] = o.describeResponse(code, resp)
+	}
+
+	if o.paths[path] == nil {
+		o.paths[path] = make(map[string]*operation)
+	}
+	o.paths[path][strings.ToLower(r.Method)] = op
+}
+
+// describeRequest adds the parameters and the body of the request struct to
+// the operation.
+func (o *OpenAPI) describeRequest(op *operation, method string, t reflect.Type, pathParams map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	form := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	hasFile := o.describeFields(op, method, t, pathParams, form)
+
+	if len(form.Properties) > 0 && op.RequestBody == nil {
+		op.RequestBody = &requestBody{
+			Content: map[string]mediaType{
+				"application/json":                  {Schema: form},
+				"application/x-www-form-urlencoded": {Schema: form},
+			},
+		}
+		if hasFile {
+			op.RequestBody.Content = map[string]mediaType{"multipart/form-data": {Schema: form}}
+		}
+	}
+}
+
+// describeFields adds the fields of the struct to the operation and the
+// members of the body to the form. The fields of an embedded struct are
+// added like the fields of the struct. True is returned if the form has a
+// file.
+func (o *OpenAPI) describeFields(op *operation, method string, t reflect.Type, pathParams map[string]bool, form *Schema) bool {
+	fields := structFields(t)
+	explicit := fields.explicit()
+	body, hasBody := fields.body()
+
+	hasFile := false
+	for _, f := range fields {
+		sf := t.Field(f.index)
+		if et, ok := embeddedStruct(sf); ok && f.in == "" {
+			hasFile = o.describeFields(op, method, et, pathParams, form) || hasFile
+			continue
+		}
+
+		in := f.in
+		if hasBody && f.index == body.index {
+			in = InBody
+		} else if !explicit && pathParams[f.name] {
+			in = InPath
+		} else if !explicit && (method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete) {
+			in = InQuery
+		}
+
+		schema, required := o.fieldSchema(sf)
+		switch in {
+		case InPath, InQuery, InHeader, InCookie:
+			op.Parameters = append(op.Parameters, parameter{
+				Name:     f.name,
+				In:       in,
+				Required: required || in == InPath,
+				Schema:   schema,
+			})
+		case InBody:
+			op.RequestBody = &requestBody{
+				Required: true,
+				Content:  map[string]mediaType{"application/json": {Schema: schema}},
+			}
+			if hasFileField(sf.Type) {
+				op.RequestBody.Content["multipart/form-data"] = mediaType{Schema: schema}
+			}
+		default:
+			form.Properties[f.name] = schema
+			if required {
+				form.Required = append(form.Required, f.name)
+			}
+			hasFile = hasFile || hasFileField(sf.Type)
+		}
+	}
+
+	return hasFile
+}
+
+// describeResponse returns the response for a status code. A struct with a
+// Body field uses the type of the Body field.
+func (o *OpenAPI) describeResponse(code int, resp interface{}) response {
+	r := response{Description: http.StatusText(code)}
+	if resp == nil {
+		return r
+	}
+
+	t := reflect.TypeOf(resp)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Struct {
+		if f, ok := structFields(t).body(); ok {
+			t = t.Field(f.index).Type
+		}
+	}
+
+	r.Content = map[string]mediaType{"application/json": {Schema: o.schemaOf(t)}}
+	return r
+}
+
+// hasFileField returns true if the type is an uploaded file or a struct with
+// an uploaded file.
+func hasFileField(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == fileHeaderType.Elem(), t == fileHeaderSliceType:
+		return true
+	case t.Kind() == reflect.Struct:
+		for j := 0; j < t.NumField(); j++ {
+			if hasFileField(t.Field(j).Type) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// openAPIPath converts an echo path like /note/:note_id to /note/{note_id}
+// and returns the names of the path parameters.
+func openAPIPath(path string) (string, map[string]bool) {
+	params := make(map[string]bool)
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			params[part[1:]] = true
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+
+	return strings.Join(parts, "/"), params
+}
+
+// MarshalJSON returns the document as JSON.
+func (o *OpenAPI) MarshalJSON() ([]byte, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	doc := struct {
+		OpenAPI    string                           `json:"openapi"`
+		Info       OpenAPIInfo                      `json:"info"`
+		Paths      map[string]map[string]*operation `json:"paths"`
+		Components struct {
+			Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+			SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+		} `json:"components"`
+	}{
+		OpenAPI: "3.1.0",
+		Info:    o.info,
+		Paths:   o.paths,
+	}
+	doc.Components.Schemas = o.schemas
+	doc.Components.SecuritySchemes = o.schemes
+
+	return json.Marshal(doc)
+}
+
+// MarshalYAML returns the document as YAML.
+func (o *OpenAPI) MarshalYAML() ([]byte, error) {
+	b, err := o.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML so the keys keep the same order.
+	node := new(yaml.Node)
+	if err = yaml.Unmarshal(b, node); err != nil {
+		return nil, err
+	}
+	blockStyle(node)
+
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err = enc.Encode(node); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), enc.Close()
+}
+
+// blockStyle removes the JSON flow style from the nodes so the YAML uses the
+// block style.
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, n := range node.Content {
+		blockStyle(n)
+	}
+}
+
+// JSONHandler returns an echo.HandlerFunc that sends the document as JSON.
+func (o *OpenAPI) JSONHandler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		b, err := o.MarshalJSON()
+		if err != nil {
+			return err
+		}
+
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, b)
+	}
+}
+
+// YAMLHandler returns an echo.HandlerFunc that sends the document as YAML.
+func (o *OpenAPI) YAMLHandler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		b, err := o.MarshalYAML()
+		if err != nil {
+			return err
+		}
+
+		return c.Blob(http.StatusOK, "application/yaml", b)
+	}
+}
@@ -0,0 +1,188 @@
+package octane_test
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type openAPIAddress struct {
+	City string `json:"city" validate:"required,max=20"`
+}
+
+type openAPIRequest struct {
+	NoteID  string    `json:"note_id" in:"path"`
+	Page    int       `json:"page" in:"query" default:"1" validate:"min=1"`
+	TraceID string    `json:"X-Trace-ID" in:"header" validate:"required,uuid4"`
+	Since   time.Time `json:"since" in:"query"`
+	Body    struct {
+		Email   string            `json:"email" validate:"required,email"`
+		Score   float64           `json:"score" validate:"gte=0,lt=10"`
+		Tags    []string          `json:"tags" validate:"max=3,dive,oneof=red blue"`
+		Labels  map[string]string `json:"labels"`
+		Home    openAPIAddress    `json:"home"`
+		Work    *openAPIAddress   `json:"work,omitempty"`
+		Ignored string            `json:"-"`
+	} `in:"body"`
+}
+
+type openAPIUpload struct {
+	Name string                `json:"name" validate:"required"`
+	File *multipart.FileHeader `json:"file"`
+}
+
+// openAPIDoc returns the document as a map to compare the parts.
+func openAPIDoc(t *testing.T, api *octane.OpenAPI) map[string]interface{} {
+	b, err := api.MarshalJSON()
+	assert.Nil(t, err)
+
+	doc := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(b, &doc))
+	return doc
+}
+
+// docJSON returns the JSON for part of the document.
+func docJSON(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	assert.Nil(t, err)
+	return string(b)
+}
+
+func TestOpenAPI(t *testing.T) {
+	api := octane.NewOpenAPI(octane.OpenAPIInfo{Title: "Notes", Version: "1.0.0"})
+	api.AddSecurityScheme("token", octane.SecurityScheme{Type: "http", Scheme: "bearer"})
+	api.Describe(octane.Route{
+		Method:      http.MethodPut,
+		Path:        "/note/:note_id",
+		OperationID: "NoteUpdate",
+		Tags:        []string{"note"},
+		Security:    []string{"token"},
+		Request:     openAPIRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:         octane.OKResponse{},
+			http.StatusNoContent:  nil,
+			http.StatusBadRequest: &octane.BadRequestResponse{},
+		},
+	})
+
+	doc := openAPIDoc(t, api)
+	assert.Equal(t, "3.1.0", doc["openapi"])
+	assert.Equal(t, `{"title":"Notes","version":"1.0.0"}`, docJSON(t, doc["info"]))
+
+	op := doc["paths"].(map[string]interface{})["/note/{note_id}"].(map[string]interface{})["put"].(map[string]interface{})
+	assert.Equal(t, "NoteUpdate", op["operationId"])
+	assert.Equal(t, `[{"token":[]}]`, docJSON(t, op["security"]))
+	assert.Equal(t, `[`+
+		`{"in":"path","name":"note_id","required":true,"schema":{"type":"string"}},`+
+		`{"in":"query","name":"page","schema":{"default":1,"minimum":1,"type":"integer"}},`+
+		`{"in":"header","name":"X-Trace-ID","required":true,"schema":{"format":"uuid","type":"string"}},`+
+		`{"in":"query","name":"since","schema":{"format":"date-time","type":"string"}}]`,
+		docJSON(t, op["parameters"]))
+
+	assert.Equal(t, `{"content":{"application/json":{"schema":{"properties":{`+
+		`"email":{"format":"email","type":"string"},`+
+		`"home":{"$ref":"#/components/schemas/openAPIAddress"},`+
+		`"labels":{"additionalProperties":{"type":"string"},"type":"object"},`+
+		`"score":{"exclusiveMaximum":10,"format":"double","minimum":0,"type":"number"},`+
+		`"tags":{"items":{"enum":["red","blue"],"type":"string"},"maxItems":3,"type":"array"},`+
+		`"work":{"$ref":"#/components/schemas/openAPIAddress"}},`+
+		`"required":["email"],"type":"object"}}},"required":true}`,
+		docJSON(t, op["requestBody"]))
+
+	assert.Equal(t, `{"200":{"content":{"application/json":{"schema":{"properties":{`+
+		`"message":{"type":"string"},"status_code":{"type":"integer"},"status_message":{"type":"string"}},`+
+		`"type":"object"}}},"description":"OK"},`+
+		`"204":{"description":"No Content"},`+
+		`"400":{"content":{"application/json":{"schema":{"properties":{`+
+		`"message":{"type":"string"},"status_code":{"type":"integer"},"status_message":{"type":"string"}},`+
+		`"type":"object"}}},"description":"Bad Request"}}`,
+		docJSON(t, op["responses"]))
+
+	assert.Equal(t, `{"schemas":{"openAPIAddress":{"properties":{"city":{"maxLength":20,"type":"string"}},`+
+		`"required":["city"],"type":"object"}},"securitySchemes":{"token":{"scheme":"bearer","type":"http"}}}`,
+		docJSON(t, doc["components"]))
+}
+
+func TestOpenAPIInferred(t *testing.T) {
+	api := octane.NewOpenAPI(octane.OpenAPIInfo{Title: "Notes", Version: "1.0.0"})
+	api.Describe(octane.Route{
+		Method: http.MethodGet,
+		Path:   "/user/:user_id/note",
+		Request: struct {
+			UserID string `json:"user_id"`
+			Limit  int    `json:"limit" validate:"max=100"`
+		}{},
+	})
+	api.Describe(octane.Route{
+		Method:  http.MethodPost,
+		Path:    "/upload",
+		Request: openAPIUpload{},
+	})
+
+	paths := openAPIDoc(t, api)["paths"].(map[string]interface{})
+
+	get := paths["/user/{user_id}/note"].(map[string]interface{})["get"].(map[string]interface{})
+	assert.Equal(t, `[`+
+		`{"in":"path","name":"user_id","required":true,"schema":{"type":"string"}},`+
+		`{"in":"query","name":"limit","schema":{"maximum":100,"type":"integer"}}]`,
+		docJSON(t, get["parameters"]))
+	assert.Nil(t, get["requestBody"])
+
+	post := paths["/upload"].(map[string]interface{})["post"].(map[string]interface{})
+	assert.Equal(t, `{"content":{"multipart/form-data":{"schema":{"properties":{`+
+		`"file":{"format":"binary","type":"string"},"name":{"type":"string"}},`+
+		`"required":["name"],"type":"object"}}}}`,
+		docJSON(t, post["requestBody"]))
+}
+
+func TestOpenAPIHandlers(t *testing.T) {
+	e := echo.New()
+	e.Binder = octane.NewBinder()
+
+	api := octane.NewOpenAPI(octane.OpenAPIInfo{Title: "Notes", Version: "1.0.0"})
+	api.Add(e, octane.Route{
+		Method:    http.MethodGet,
+		Path:      "/healthcheck",
+		Responses: map[int]interface{}{http.StatusOK: octane.OKResponse{}},
+	}, func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/openapi.json", api.JSONHandler())
+	e.GET("/openapi.yaml", api.YAMLHandler())
+
+	// The route is added to echo.
+	r := httptest.NewRequest(http.MethodGet, "/healthcheck", nil)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	r = httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, echo.MIMEApplicationJSON, w.Header().Get(echo.HeaderContentType))
+	assert.True(t, strings.HasPrefix(w.Body.String(), `{"openapi":"3.1.0","info":`))
+	fromJSON := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &fromJSON))
+
+	r = httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil)
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/yaml", w.Header().Get(echo.HeaderContentType))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "openapi: 3.1.0\ninfo:\n  title: Notes\n"))
+	fromYAML := make(map[string]interface{})
+	assert.Nil(t, yaml.Unmarshal(w.Body.Bytes(), &fromYAML))
+
+	// Both formats contain the same document.
+	assert.Equal(t, docJSON(t, fromJSON), docJSON(t, fromYAML))
+}
@@ -0,0 +1,158 @@
+package octane
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// BinderOption configures a Binder.
+type BinderOption func(*Binder)
+
+// TranslationFunc registers the validation messages for a translator. The
+// RegisterDefaultTranslations functions from the validator v10 translations
+// packages and from the octane translations packages can be used.
+type TranslationFunc func(v *validator.Validate, trans ut.Translator) error
+
+// translation contains a locale and its validation messages.
+type translation struct {
+	locale   locales.Translator
+	register TranslationFunc
+}
+
+// WithTranslation registers the validation messages for a locale with the
+// default validator. The locale is picked using the Accept-Language header
+// of the request. The first locale registered is used when no locale matches
+// the header.
+func WithTranslation(locale locales.Translator, register TranslationFunc) BinderOption {
+	return func(b *Binder) {
+		b.translations = append(b.translations, translation{
+			locale:   locale,
+			register: register,
+		})
+	}
+}
+
+// WithStrict will reject the JSON keys and the form keys that don't match a
+// field of the request struct. To only reject unknown keys for a single
+// request struct, embed Strict in the struct instead.
+func WithStrict() BinderOption {
+	return func(b *Binder) {
+		b.strict = true
+	}
+}
+
+// WithMaxMemory sets the number of bytes of a multipart form that are stored
+// in memory. The rest of the form is stored in temporary files. The default
+// is 32 MB.
+func WithMaxMemory(size int64) BinderOption {
+	return func(b *Binder) {
+		b.maxMemory = size
+	}
+}
+
+// WithMaxFileSize sets the max number of bytes of each file uploaded in a
+// multipart form. A larger file is returned as a field error before the
+// request struct is validated. The default of 0 allows any size.
+func WithMaxFileSize(size int64) BinderOption {
+	return func(b *Binder) {
+		b.maxFileSize = size
+	}
+}
+
+// WithBodyDecoder registers a body decoder for a media type like
+// "application/yaml". The decoders for XML, MessagePack, and CBOR are
+// registered by default and can be replaced. A nil decoder removes the media
+// type so the body returns 415 Unsupported Media Type.
+func WithBodyDecoder(mediaType string, dec BodyDecoder) BinderOption {
+	return func(b *Binder) {
+		mediaType = strings.ToLower(mediaType)
+		if dec == nil {
+			delete(b.bodyDecoders, mediaType)
+			return
+		}
+		b.bodyDecoders[mediaType] = dec
+	}
+}
+
+// WithMaxBodySize sets the max number of bytes read from the body of each
+// request. For a compressed body, the limit applies to the decompressed
+// body. A larger body returns 413 Request Entity Too Large. Use the
+// MaxBodySize middleware to change the size for a route. The default of 0
+// allows any size.
+func WithMaxBodySize(size int64) BinderOption {
+	return func(b *Binder) {
+		b.maxBodySize = size
+	}
+}
+
+// WithTypeDecoder registers a TypeDecoder for each type so the path
+// parameters, query string values, headers, cookies, form fields, and JSON
+// values of the types are converted the same way. Pass a value of each type
+// like uuid.UUID{}.
+func WithTypeDecoder(dec TypeDecoder, types ...interface{}) BinderOption {
+	return func(b *Binder) {
+		for _, t := range types {
+			b.typeDecoders[reflect.TypeOf(t)] = dec
+		}
+	}
+}
+
+// WithValidation registers a validation rule for the tag.
+func WithValidation(tag string, fn validator.Func) BinderOption {
+	return func(b *Binder) {
+		if err := b.validate.RegisterValidation(tag, fn); err != nil {
+			panic(fmt.Sprintf("octane: could not register the %v validation: %v", tag, err))
+		}
+	}
+}
+
+// WithValidationCtx registers a validation rule for the tag that receives the
+// context of the request. Use the context to read the values set by
+// middleware or to cancel a database query when the request is canceled.
+func WithValidationCtx(tag string, fn validator.FuncCtx) BinderOption {
+	return func(b *Binder) {
+		if err := b.validate.RegisterValidationCtx(tag, fn); err != nil {
+			panic(fmt.Sprintf("octane: could not register the %v validation: %v", tag, err))
+		}
+	}
+}
+
+// WithStructValidation registers a struct level validation for each type to
+// validate fields that depend on each other like an end date that must be
+// after a start date. Pass a value of each type.
+func WithStructValidation(fn validator.StructLevelFunc, types ...interface{}) BinderOption {
+	return func(b *Binder) {
+		b.validate.RegisterStructValidation(fn, types...)
+	}
+}
+
+// WithStructValidationCtx registers a struct level validation for each type
+// that receives the context of the request.
+func WithStructValidationCtx(fn validator.StructLevelFuncCtx, types ...interface{}) BinderOption {
+	return func(b *Binder) {
+		b.validate.RegisterStructValidationCtx(fn, types...)
+	}
+}
+
+// WithAlias registers a tag that is replaced by one or more tags like
+// "iscolor" for "hexcolor|rgb|rgba|hsl|hsla".
+func WithAlias(alias, tags string) BinderOption {
+	return func(b *Binder) {
+		b.validate.RegisterAlias(alias, tags)
+	}
+}
+
+// WithValidator sets the engine used to validate each request struct. The
+// default validator uses go-playground/validator v10. The options that
+// register translations, validations, and aliases only apply to the default
+// validator.
+func WithValidator(v Validator) BinderOption {
+	return func(b *Binder) {
+		b.validator = v
+	}
+}
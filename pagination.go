@@ -0,0 +1,216 @@
+package octane
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultPageLimit is the number of items on a page when the limit is not
+// set. The limit can be at most 100.
+const DefaultPageLimit = 20
+
+// PageRequest contains the query string values of a page of items. Embed it
+// in a request to bind the values with the rest of the request. A page is
+// either at an offset or at a cursor from the meta of the previous page.
+// The cursor takes precedence over the offset.
+type PageRequest struct {
+	// Limit is the number of items on the page.
+	// example: 20
+	// minimum: 1
+	// maximum: 100
+	// in: query
+	Limit int `json:"limit" in:"query" validate:"omitempty,min=1,max=100"`
+	// Offset is the number of items to skip.
+	// example: 0
+	// minimum: 0
+	// in: query
+	Offset int `json:"offset" in:"query" validate:"min=0"`
+	// Cursor is the next_cursor or the prev_cursor of another page.
+	// in: query
+	Cursor string `json:"cursor" in:"query"`
+}
+
+// PageLimit returns the limit or DefaultPageLimit if the limit is not set.
+func (p PageRequest) PageLimit() int {
+	if p.Limit <= 0 {
+		return DefaultPageLimit
+	}
+
+	return p.Limit
+}
+
+// PageCursor returns the cursor with the number of key values. An empty
+// cursor returns the zero Cursor. A cursor that can't be decoded or has a
+// different number of values returns a ValidationError.
+func (p PageRequest) PageCursor(keys int) (Cursor, error) {
+	if len(p.Cursor) == 0 {
+		return Cursor{}, nil
+	}
+
+	cur, err := ParseCursor(p.Cursor)
+	if err != nil || len(cur.Values) != keys {
+		return Cursor{}, &ValidationError{Errors: []FieldError{
+			{Field: "cursor", Rule: "cursor", Message: "cursor is invalid"},
+		}}
+	}
+
+	return cur, nil
+}
+
+// Meta returns the meta of an offset page with the total number of items.
+func (p PageRequest) Meta(total int) PageMeta {
+	return PageMeta{
+		Total:  total,
+		Limit:  p.PageLimit(),
+		Offset: p.Offset,
+	}
+}
+
+// Cursor is the position of a page for keyset pagination. It contains the
+// sort key values of the item at the edge of a page.
+type Cursor struct {
+	// Values are the sort key values of the item in the order of the sort.
+	Values []interface{}
+	// Before is true for the page before the item instead of after it.
+	Before bool
+}
+
+// cursorJSON is the encoded form of a Cursor.
+type cursorJSON struct {
+	Values []interface{} `json:"v"`
+	Before bool          `json:"b,omitempty"`
+}
+
+// IsZero returns true if the cursor doesn't have a position.
+func (c Cursor) IsZero() bool {
+	return len(c.Values) == 0
+}
+
+// String returns the cursor as an opaque URL safe string.
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+
+	b, err := json.Marshal(cursorJSON{Values: c.Values, Before: c.Before})
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseCursor returns the Cursor from the string of Cursor.String. A number
+// is returned as an int64 if it's an integer and a float64 otherwise.
+func ParseCursor(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("cursor could not be decoded: %v", err.Error())
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	cj := cursorJSON{}
+	if err = dec.Decode(&cj); err != nil {
+		return Cursor{}, fmt.Errorf("cursor could not be decoded: %v", err.Error())
+	}
+
+	for i, v := range cj.Values {
+		switch val := v.(type) {
+		case json.Number:
+			if n, err := val.Int64(); err == nil {
+				cj.Values[i] = n
+			} else if f, err := val.Float64(); err == nil {
+				cj.Values[i] = f
+			}
+		case nil, string, bool:
+		default:
+			return Cursor{}, fmt.Errorf("cursor value %v is not a string, number, or boolean", v)
+		}
+	}
+
+	return Cursor{Values: cj.Values, Before: cj.Before}, nil
+}
+
+// PageMeta contains the position of a page.
+// swagger:model
+type PageMeta struct {
+	// Total is the number of items on every page.
+	// example: 42
+	// required: true
+	Total int `json:"total"`
+	// Limit is the number of items on a page.
+	// example: 20
+	// required: true
+	Limit int `json:"limit"`
+	// Offset is the number of items before the page.
+	// example: 0
+	Offset int `json:"offset,omitempty"`
+	// NextCursor is the cursor of the next page if there is one.
+	// example: eyJ2IjpbIm5vdGUiXX0
+	NextCursor string `json:"next_cursor,omitempty"`
+	// PrevCursor is the cursor of the previous page if there is one.
+	// example: eyJ2IjpbIm5vdGUiXSwiYiI6dHJ1ZX0
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// links returns the RFC 8288 Link header values for the next and the
+// previous page of the request URL. The cursors are used when they are set,
+// otherwise the offset is moved by the limit.
+func (m PageMeta) links(r *http.Request) []string {
+	links := make([]string, 0, 2)
+	add := func(rel, key, value string) {
+		u := *r.URL
+		q := u.Query()
+		q.Del("cursor")
+		q.Del("offset")
+		q.Set(key, value)
+		if m.Limit > 0 {
+			q.Set("limit", strconv.Itoa(m.Limit))
+		}
+		u.RawQuery = q.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel))
+	}
+
+	switch {
+	case len(m.NextCursor) > 0:
+		add("next", "cursor", m.NextCursor)
+	case len(m.PrevCursor) == 0 && m.Limit > 0 && m.Offset+m.Limit < m.Total:
+		add("next", "offset", strconv.Itoa(m.Offset+m.Limit))
+	}
+
+	switch {
+	case len(m.PrevCursor) > 0:
+		add("prev", "cursor", m.PrevCursor)
+	case len(m.NextCursor) == 0 && m.Offset > 0:
+		prev := m.Offset - m.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		add("prev", "offset", strconv.Itoa(prev))
+	}
+
+	return links
+}
+
+// PageResponse sends a page of content like DataResponse with the meta of
+// the page in a meta member. The links to the next and the previous page
+// are sent in the Link header so a CSV response can be paged too.
+func (c *ResponseJSON) PageResponse(code int, i interface{}, meta PageMeta) error {
+	if links := meta.links(c.Request()); len(links) > 0 {
+		c.Response().Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	ok, err := writeData(c, code, i, &meta)
+	if !ok {
+		return c.MessageResponse(notAcceptable(responseEncoders), http.StatusNotAcceptable)
+	}
+
+	return err
+}
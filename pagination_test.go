@@ -0,0 +1,141 @@
+package octane_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// pageRequest is a request with the page values embedded.
+type pageRequest struct {
+	UserID string `json:"user_id" in:"path"`
+	octane.PageRequest
+}
+
+func TestPageRequestBind(t *testing.T) {
+	e := echo.New()
+	e.Binder = octane.NewBinder()
+
+	var req pageRequest
+	var err error
+	e.GET("/user/:user_id/note", func(c echo.Context) error {
+		req = pageRequest{}
+		err = c.Bind(&req)
+		return nil
+	})
+
+	get := func(target string) {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	get("/user/10/note?limit=5&offset=10&cursor=abc")
+	assert.Nil(t, err)
+	assert.Equal(t, "10", req.UserID)
+	assert.Equal(t, octane.PageRequest{Limit: 5, Offset: 10, Cursor: "abc"}, req.PageRequest)
+	assert.Equal(t, 5, req.PageLimit())
+
+	get("/user/10/note")
+	assert.Nil(t, err)
+	assert.Equal(t, octane.DefaultPageLimit, req.PageLimit())
+
+	get("/user/10/note?limit=500&offset=-1")
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{
+		{Field: "limit", Rule: "max", Param: "100", Message: "limit must be at most 100"},
+		{Field: "offset", Rule: "min", Param: "0", Message: "offset must be at least 0"},
+	}}, err)
+}
+
+func TestCursor(t *testing.T) {
+	cur := octane.Cursor{Values: []interface{}{"hello", int64(10), 1.5}, Before: true}
+	got, err := octane.ParseCursor(cur.String())
+	assert.Nil(t, err)
+	assert.Equal(t, cur, got)
+
+	assert.Equal(t, "", octane.Cursor{}.String())
+	_, err = octane.ParseCursor("not a cursor")
+	assert.NotNil(t, err)
+
+	page := octane.PageRequest{Cursor: cur.String()}
+	got, err = page.PageCursor(3)
+	assert.Nil(t, err)
+	assert.Equal(t, cur, got)
+
+	_, err = page.PageCursor(2)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{
+		{Field: "cursor", Rule: "cursor", Message: "cursor is invalid"},
+	}}, err)
+
+	got, err = octane.PageRequest{}.PageCursor(2)
+	assert.Nil(t, err)
+	assert.True(t, got.IsZero())
+}
+
+// pageResponse sends a page of notes for the target.
+func pageResponse(target string, accept string, meta octane.PageMeta) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if len(accept) > 0 {
+		req.Header.Set(echo.HeaderAccept, accept)
+	}
+	rec := httptest.NewRecorder()
+	c := &octane.ResponseJSON{Context: e.NewContext(req, rec)}
+	c.PageResponse(http.StatusOK, []negotiateAuthor{{Email: "jsmith@example.com"}}, meta)
+
+	return rec
+}
+
+func TestPageResponse(t *testing.T) {
+	rec := pageResponse("/note?limit=1&tag=red", "", octane.PageMeta{
+		Total:      3,
+		Limit:      1,
+		NextCursor: "bmV4dA",
+		PrevCursor: "cHJldg",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `</note?cursor=bmV4dA&limit=1&tag=red>; rel="next", `+
+		`</note?cursor=cHJldg&limit=1&tag=red>; rel="prev"`, rec.Result().Header.Get("Link"))
+	assert.JSONEq(t, `{
+		"data": [{"email": "jsmith@example.com"}],
+		"meta": {"total": 3, "limit": 1, "next_cursor": "bmV4dA", "prev_cursor": "cHJldg"},
+		"status_code": 200,
+		"status_message": "OK"
+	}`, rec.Body.String())
+
+	// An offset page links to the pages around it.
+	rec = pageResponse("/note?offset=3&limit=2", "text/csv", octane.PageRequest{Limit: 2, Offset: 3}.Meta(10))
+	assert.Equal(t, `</note?limit=2&offset=5>; rel="next", </note?limit=2&offset=1>; rel="prev"`,
+		rec.Result().Header.Get("Link"))
+	assert.Equal(t, "email\njsmith@example.com\n", rec.Body.String())
+
+	// The first and only page doesn't have links.
+	rec = pageResponse("/note", "", octane.PageRequest{}.Meta(1))
+	assert.Equal(t, "", rec.Result().Header.Get("Link"))
+	assert.JSONEq(t, `{
+		"data": [{"email": "jsmith@example.com"}],
+		"meta": {"total": 1, "limit": 20},
+		"status_code": 200,
+		"status_message": "OK"
+	}`, rec.Body.String())
+}
+
+func TestPageOpenAPI(t *testing.T) {
+	api := octane.NewOpenAPI(octane.OpenAPIInfo{Title: "Notes", Version: "1.0.0"})
+	api.Describe(octane.Route{
+		Method:  http.MethodGet,
+		Path:    "/user/:user_id/note",
+		Request: pageRequest{},
+	})
+
+	paths := openAPIDoc(t, api)["paths"].(map[string]interface{})
+	get := paths["/user/{user_id}/note"].(map[string]interface{})["get"].(map[string]interface{})
+	assert.Equal(t, `[`+
+		`{"in":"path","name":"user_id","required":true,"schema":{"type":"string"}},`+
+		`{"in":"query","name":"limit","schema":{"maximum":100,"minimum":1,"type":"integer"}},`+
+		`{"in":"query","name":"offset","schema":{"minimum":0,"type":"integer"}},`+
+		`{"in":"query","name":"cursor","schema":{"type":"string"}}]`,
+		docJSON(t, get["parameters"]))
+}
@@ -0,0 +1,148 @@
+package octane
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationProblemJSON is the media type of a problem details response.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// keyProblemFormat is the key of the ProblemFormat in the echo context.
+const keyProblemFormat = "octane.problem_format"
+
+// ProblemFormat selects when the error responses are sent as
+// application/problem+json from RFC 9457 instead of the legacy message,
+// status_code, and status_message members.
+type ProblemFormat int
+
+const (
+	// ProblemNever sends the legacy error responses.
+	ProblemNever ProblemFormat = iota
+	// ProblemAlways sends every error response as problem details.
+	ProblemAlways
+	// ProblemAccept sends problem details when the client prefers
+	// application/problem+json to application/json in the Accept header.
+	ProblemAccept
+)
+
+// ProblemResponse is a failure in the RFC 9457 problem details format.
+// swagger:response ProblemResponse
+type ProblemResponse struct {
+	// in: body
+	Body Problem
+}
+
+// Problem contains the members of an application/problem+json response.
+// swagger:model
+type Problem struct {
+	// Type is a URI that identifies the problem type.
+	// example: about:blank
+	// required: true
+	Type string `json:"type"`
+	// Title is the summary of the problem type.
+	// example: Unprocessable Entity
+	// required: true
+	Title string `json:"title"`
+	// Status contains the HTTP status code.
+	// example: 422
+	// required: true
+	Status int `json:"status"`
+	// Detail contains a user friendly message.
+	// example: The data submitted failed validation.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI that identifies the request with the problem.
+	// example: /api/v1/note
+	Instance string `json:"instance,omitempty"`
+	// Errors contains each field that failed validation.
+	Errors []FieldError `json:"errors,omitempty"`
+	// Extensions contains other members that are added to the response.
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem returns a Problem for a status code with the title set to the
+// status text.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// MarshalJSON adds the extension members to the standard members. An
+// extension can't replace a standard member.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	b, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	members := make(map[string]interface{}, len(p.Extensions))
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	standard := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(b, &standard); err != nil {
+		return nil, err
+	}
+	for k, v := range standard {
+		members[k] = v
+	}
+
+	return json.Marshal(members)
+}
+
+// ProblemDetails returns an echo.MiddlewareFunc that sets when the error
+// responses from ResponseJSON are sent as problem details. Use it with
+// e.Use() to set the format for every route or pass it to a single route.
+func ProblemDetails(format ProblemFormat) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(keyProblemFormat, format)
+			return next(c)
+		}
+	}
+}
+
+// useProblem returns true if the error responses should be sent as problem
+// details.
+func useProblem(c echo.Context) bool {
+	format, _ := c.Get(keyProblemFormat).(ProblemFormat)
+	switch format {
+	case ProblemAlways:
+		return true
+	case ProblemAccept:
+		c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+		for _, mt := range parseAccept(c.Request().Header.Get(echo.HeaderAccept)) {
+			switch mt {
+			case MIMEApplicationProblemJSON:
+				return true
+			case echo.MIMEApplicationJSON, "application/*", "*/*":
+				return false
+			}
+		}
+	}
+
+	return false
+}
+
+// ProblemResponse sends problem details with the status code of the problem.
+// The instance is set to the path of the request if it's empty.
+func (c *ResponseJSON) ProblemResponse(p *Problem) error {
+	if len(p.Instance) == 0 {
+		p.Instance = c.Request().URL.Path
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	return c.Blob(p.Status, MIMEApplicationProblemJSON, b)
+}
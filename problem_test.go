@@ -0,0 +1,122 @@
+package octane_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// problemContext returns a ResponseJSON with the problem format set like
+// the ProblemDetails middleware.
+func problemContext(format octane.ProblemFormat, accept string) (*octane.ResponseJSON, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/note", nil)
+	if len(accept) > 0 {
+		req.Header.Set(echo.HeaderAccept, accept)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := octane.ProblemDetails(format)(func(echo.Context) error { return nil })
+	h(c)
+
+	return &octane.ResponseJSON{Context: c}, rec
+}
+
+func TestProblemValidation(t *testing.T) {
+	c, rec := problemContext(octane.ProblemAlways, "")
+
+	err := &octane.ValidationError{Errors: []octane.FieldError{
+		{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+	}}
+	assert.Equal(t, err, c.BindErrorResponse(err))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, octane.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Unprocessable Entity",
+		"status": 422,
+		"detail": "the data submitted failed validation",
+		"instance": "/api/v1/note",
+		"errors": [
+			{"field": "email", "rule": "email", "message": "email must be a valid email address"}
+		]
+	}`, rec.Body.String())
+}
+
+func TestProblemMessage(t *testing.T) {
+	c, rec := problemContext(octane.ProblemAlways, "")
+
+	assert.Nil(t, c.BadRequestResponse("invalid note"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "invalid note",
+		"instance": "/api/v1/note"
+	}`, rec.Body.String())
+
+	// A success message keeps the legacy format.
+	c, rec = problemContext(octane.ProblemAlways, "")
+	assert.Nil(t, c.OKResponse("note updated"))
+	assert.JSONEq(t, `{
+		"message": "note updated",
+		"status_code": 200,
+		"status_message": "OK"
+	}`, rec.Body.String())
+}
+
+func TestProblemAccept(t *testing.T) {
+	tests := []struct {
+		format  octane.ProblemFormat
+		accept  string
+		problem bool
+	}{
+		{octane.ProblemNever, octane.MIMEApplicationProblemJSON, false},
+		{octane.ProblemAccept, "", false},
+		{octane.ProblemAccept, octane.MIMEApplicationProblemJSON, true},
+		{octane.ProblemAccept, "application/json, application/problem+json", false},
+		{octane.ProblemAccept, "application/json;q=0.5, application/problem+json", true},
+		{octane.ProblemAccept, "*/*", false},
+	}
+
+	for _, tc := range tests {
+		c, rec := problemContext(tc.format, tc.accept)
+		c.NotFoundResponse("note not found")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code, tc.accept)
+		if tc.problem {
+			assert.Equal(t, octane.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType), tc.accept)
+		} else {
+			assert.Equal(t, echo.MIMEApplicationJSONCharsetUTF8, rec.Header().Get(echo.HeaderContentType), tc.accept)
+		}
+	}
+}
+
+func TestProblemExtensions(t *testing.T) {
+	c, rec := problemContext(octane.ProblemNever, "")
+
+	p := octane.NewProblem(http.StatusForbidden, "your balance is too low")
+	p.Type = "https://example.com/probs/out-of-credit"
+	p.Instance = "/account/12345/msgs/abc"
+	p.Extensions = map[string]interface{}{
+		"balance": 30,
+		"status":  "ignored",
+	}
+
+	assert.Nil(t, c.ProblemResponse(p))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "Forbidden",
+		"status": 403,
+		"detail": "your balance is too low",
+		"instance": "/account/12345/msgs/abc",
+		"balance": 30
+	}`, rec.Body.String())
+}
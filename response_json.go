@@ -1,9 +1,7 @@
 package octane
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -106,6 +104,72 @@ type NotFoundResponse struct {
 	}
 }
 
+// PreconditionFailedResponse is a failure.
+// swagger:response PreconditionFailedResponse
+type PreconditionFailedResponse struct {
+	// in: body
+	Body struct {
+		// Message contains a user friendly message.
+		// example: The resource was changed by another request.
+		// required: true
+		Message string `json:"message"`
+		// Code contains the HTTP status code.
+		// example: 412
+		// required: true
+		StatusCode int `json:"status_code"`
+		// Status contains the string of the HTTP status.
+		// example: Precondition Failed
+		// required: true
+		StatusMessage string `json:"status_message"`
+	}
+}
+
+// ValidationErrorResponse is a failure.
+// swagger:response ValidationErrorResponse
+type ValidationErrorResponse struct {
+	// in: body
+	Body struct {
+		// Message contains a user friendly message.
+		// example: The data submitted failed validation.
+		// required: true
+		Message string `json:"message"`
+		// Code contains the HTTP status code.
+		// example: 422
+		// required: true
+		StatusCode int `json:"status_code"`
+		// Status contains the string of the HTTP status.
+		// example: Unprocessable Entity
+		// required: true
+		StatusMessage string `json:"status_message"`
+		// Errors contains each field that failed validation.
+		// required: true
+		Errors []FieldError `json:"errors"`
+	}
+}
+
+// HTTPErrorResponse is a failure from an HTTPError with details.
+// swagger:response HTTPErrorResponse
+type HTTPErrorResponse struct {
+	// in: body
+	Body struct {
+		// Message contains a user friendly message.
+		// example: The note is locked.
+		// required: true
+		Message string `json:"message"`
+		// Code contains the HTTP status code.
+		// example: 409
+		// required: true
+		StatusCode int `json:"status_code"`
+		// Status contains the string of the HTTP status.
+		// example: Conflict
+		// required: true
+		StatusMessage string `json:"status_message"`
+		// Details contains more information about the failure.
+		// required: true
+		Details interface{} `json:"details"`
+	}
+}
+
 // InternalServerErrorResponse is a failure.
 // swagger:response InternalServerErrorResponse
 type InternalServerErrorResponse struct {
@@ -131,17 +195,21 @@ type ResponseJSON struct {
 	echo.Context
 }
 
-// MessageResponse sends a JSON message with a status code.
+// MessageResponse sends a JSON message with a status code. An error status
+// code is sent as problem details when it's selected with ProblemDetails.
+// Only an error writing the response is returned so the response isn't
+// handled again. Return an HTTPError instead to send an error that is also
+// logged.
 func (c *ResponseJSON) MessageResponse(message string, statusCode int) error {
+	if statusCode >= 400 && useProblem(c) {
+		return c.ProblemResponse(NewProblem(statusCode, message))
+	}
+
 	resp := new(OKResponse)
 	resp.Body.Message = message
 	resp.Body.StatusCode = statusCode
 	resp.Body.StatusMessage = http.StatusText(statusCode)
-	err := c.JSON(resp.Body.StatusCode, resp.Body)
-	if statusCode >= 400 {
-		err = errors.New(message)
-	}
-	return err
+	return c.JSON(resp.Body.StatusCode, resp.Body)
 }
 
 // OKResponse sends 200.
@@ -169,23 +237,96 @@ func (c *ResponseJSON) InternalServerErrorResponse(message string) error {
 	return c.MessageResponse(message, http.StatusInternalServerError)
 }
 
-// DataResponse sends content with a status_code and a status_message to the response writer.
-func (c *ResponseJSON) DataResponse(code int, i interface{}) error {
-	c.Response().WriteHeader(code)
-	c.Response().Header().Set(echo.HeaderContentType, "application/json")
+// BindErrorResponse sends the error returned from Bind. A ValidationError
+// sends 422 with each failed field, an error with a status code like an
+// UnsupportedMediaTypeError sends that status code, and any other error
+// sends 400.
+func (c *ResponseJSON) BindErrorResponse(err error) error {
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		var sc statusCoder
+		code, message := http.StatusBadRequest, err.Error()
+		if errors.As(err, &sc) {
+			code, message = sc.StatusCode(), sc.(error).Error()
+		}
+		if merr := c.MessageResponse(message, code); merr != nil {
+			return merr
+		}
+		return err
+	}
 
-	f := map[string]interface{}{
-		"data":           i,
-		"status_code":    code,
-		"status_message": http.StatusText(code),
+	if useProblem(c) {
+		p := NewProblem(ve.StatusCode(), "the data submitted failed validation")
+		p.Errors = ve.Errors
+		if perr := c.ProblemResponse(p); perr != nil {
+			return perr
+		}
+		return err
 	}
 
-	b, err := json.Marshal(f)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	resp := new(ValidationErrorResponse)
+	resp.Body.Message = "the data submitted failed validation"
+	resp.Body.StatusCode = ve.StatusCode()
+	resp.Body.StatusMessage = http.StatusText(resp.Body.StatusCode)
+	resp.Body.Errors = ve.Errors
+	if jerr := c.JSON(resp.Body.StatusCode, resp.Body); jerr != nil {
+		return jerr
 	}
 
-	fmt.Fprint(c.Response().Writer, string(b))
+	return err
+}
+
+// ErrorResponse sends an error returned from a handler. An HTTPError sends
+// the public message with the details, a ValidationError sends 422 with each
+// failed field, an error with a status code or an echo.HTTPError sends that
+// status code, and any other error sends 500 without the error message. The
+// error is returned so it can be logged.
+func (c *ResponseJSON) ErrorResponse(err error) error {
+	var he *HTTPError
+	var ve *ValidationError
+	if errors.As(err, &he) && he.Details != nil {
+		if derr := c.detailsResponse(he); derr != nil {
+			return derr
+		}
+		return err
+	} else if he == nil && errors.As(err, &ve) {
+		return c.BindErrorResponse(err)
+	}
+
+	code, message := handlerStatus(err)
+	if merr := c.MessageResponse(message, code); code < 400 {
+		return merr
+	}
+
+	return err
+}
 
-	return nil
+// detailsResponse sends the public message of an HTTPError with the details.
+func (c *ResponseJSON) detailsResponse(he *HTTPError) error {
+	code := he.StatusCode()
+	if useProblem(c) {
+		p := NewProblem(code, he.PublicMessage())
+		p.Extensions = map[string]interface{}{"details": he.Details}
+		return c.ProblemResponse(p)
+	}
+
+	resp := new(HTTPErrorResponse)
+	resp.Body.Message = he.PublicMessage()
+	resp.Body.StatusCode = code
+	resp.Body.StatusMessage = http.StatusText(code)
+	resp.Body.Details = he.Details
+	return c.JSON(code, resp.Body)
+}
+
+// DataResponse sends content with a status_code and a status_message to the
+// response writer. The format is picked from the Accept header: JSON,
+// XML, and MessagePack send the content with the status, and CSV sends the
+// rows of the content. If none of the formats are acceptable, 406 is sent.
+func (c *ResponseJSON) DataResponse(code int, i interface{}) error {
+	ok, err := writeData(c, code, i, nil)
+	if !ok {
+		return c.MessageResponse(notAcceptable(responseEncoders), http.StatusNotAcceptable)
+	}
+
+	return err
 }
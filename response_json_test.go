@@ -0,0 +1,64 @@
+package octane_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindErrorResponse(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := &octane.ResponseJSON{Context: e.NewContext(req, rec)}
+
+	err := &octane.ValidationError{Errors: []octane.FieldError{
+		{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+	}}
+	assert.Equal(t, err, c.BindErrorResponse(err))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{
+		"message": "the data submitted failed validation",
+		"status_code": 422,
+		"status_message": "Unprocessable Entity",
+		"errors": [
+			{"field": "email", "rule": "email", "message": "email must be a valid email address"}
+		]
+	}`, rec.Body.String())
+}
+
+func TestBindErrorResponseBadRequest(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := &octane.ResponseJSON{Context: e.NewContext(req, rec)}
+
+	assert.EqualError(t, c.BindErrorResponse(errors.New("bad data")), "bad data")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{
+		"message": "bad data",
+		"status_code": 400,
+		"status_message": "Bad Request"
+	}`, rec.Body.String())
+}
+
+func TestBindErrorResponseStatusCode(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := &octane.ResponseJSON{Context: e.NewContext(req, rec)}
+
+	err := &octane.UnsupportedMediaTypeError{MediaType: "application/yaml"}
+	assert.EqualError(t, c.BindErrorResponse(err), "unsupported media type: application/yaml")
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	assert.JSONEq(t, `{
+		"message": "unsupported media type: application/yaml",
+		"status_code": 415,
+		"status_message": "Unsupported Media Type"
+	}`, rec.Body.String())
+}
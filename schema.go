@@ -0,0 +1,359 @@
+package octane
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Schema is a JSON Schema in an OpenAPI document.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int64             `json:"minLength,omitempty"`
+	MaxLength            *int64             `json:"maxLength,omitempty"`
+	MinItems             *int64             `json:"minItems,omitempty"`
+	MaxItems             *int64             `json:"maxItems,omitempty"`
+
+	// nullable is true if the value can be null.
+	nullable bool
+}
+
+// UnmarshalJSON reads a schema from an OpenAPI 3.1, an OpenAPI 3.0, or a
+// Swagger 2.0 document. A list of types with null and the nullable member
+// allow a null value. The boolean exclusiveMinimum and exclusiveMaximum
+// members are converted to the numbers used by OpenAPI 3.1.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	type schema Schema
+	aux := struct {
+		*schema
+		Type             json.RawMessage `json:"type"`
+		Nullable         bool            `json:"nullable"`
+		ExclusiveMinimum json.RawMessage `json:"exclusiveMinimum"`
+		ExclusiveMaximum json.RawMessage `json:"exclusiveMaximum"`
+	}{schema: (*schema)(s)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	s.nullable = aux.Nullable
+	if len(aux.Type) > 0 && aux.Type[0] == '[' {
+		var types []string
+		if err := json.Unmarshal(aux.Type, &types); err != nil {
+			return err
+		}
+		for _, t := range types {
+			if t == "null" {
+				s.nullable = true
+			} else if s.Type == "" {
+				s.Type = t
+			}
+		}
+	} else if len(aux.Type) > 0 {
+		if err := json.Unmarshal(aux.Type, &s.Type); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if s.ExclusiveMinimum, s.Minimum, err = exclusiveLimit(aux.ExclusiveMinimum, s.Minimum); err != nil {
+		return err
+	}
+	s.ExclusiveMaximum, s.Maximum, err = exclusiveLimit(aux.ExclusiveMaximum, s.Maximum)
+	return err
+}
+
+// exclusiveLimit returns the exclusive limit and the inclusive limit. A
+// boolean value makes the inclusive limit exclusive.
+func exclusiveLimit(raw json.RawMessage, limit *float64) (*float64, *float64, error) {
+	switch {
+	case len(raw) == 0:
+		return nil, limit, nil
+	case string(raw) == "true":
+		return limit, nil, nil
+	case string(raw) == "false":
+		return nil, limit, nil
+	}
+
+	f := new(float64)
+	if err := json.Unmarshal(raw, f); err != nil {
+		return nil, nil, err
+	}
+
+	return f, limit, nil
+}
+
+// schemaFormats are the formats for the validation rules.
+var schemaFormats = map[string]string{
+	"email":         "email",
+	"url":           "uri",
+	"uri":           "uri",
+	"uuid":          "uuid",
+	"uuid3":         "uuid",
+	"uuid4":         "uuid",
+	"uuid5":         "uuid",
+	"uuid_rfc4122":  "uuid",
+	"uuid4_rfc4122": "uuid",
+	"ipv4":          "ipv4",
+	"ipv6":          "ipv6",
+	"hostname":      "hostname",
+	"datetime":      "date-time",
+}
+
+// schemaPatterns are the patterns for the validation rules.
+var schemaPatterns = map[string]string{
+	"alpha":    "^[a-zA-Z]+$",
+	"alphanum": "^[a-zA-Z0-9]+$",
+	"numeric":  "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"e164":     "^\\+[1-9]?[0-9]{7,14}$",
+}
+
+// schemaOf returns the schema for a type. A named struct is added to the
+// components of the document and referenced.
+func (o *OpenAPI) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == fileHeaderType.Elem():
+		return &Schema{Type: "string", Format: "binary"}
+	case reflect.PtrTo(t).Implements(textMarshalerType) && t.Kind() != reflect.Struct:
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: o.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: o.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return o.objectSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + o.componentName(t)}
+	}
+
+	return &Schema{}
+}
+
+// componentName returns the name of a named struct in the components of the
+// document. The schema is added the first time the type is seen. Types with
+// the same name from different scopes get a number after the name.
+func (o *OpenAPI) componentName(t reflect.Type) string {
+	if name, ok := o.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	for i := 2; o.schemas[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", t.Name(), i)
+	}
+
+	// Store the name before the fields so a recursive type stops.
+	o.names[t] = name
+	o.schemas[name] = &Schema{}
+	*o.schemas[name] = *o.objectSchema(t)
+
+	return name
+}
+
+// objectSchema returns the schema for the fields of a struct using the json
+// tag names. The fields of an embedded struct are promoted.
+func (o *OpenAPI) objectSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for j := 0; j < t.NumField(); j++ {
+		sf := t.Field(j)
+		name := jsonName(sf)
+		if name == "-" || sf.Type == strictType {
+			continue
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded := o.objectSchema(ft)
+			for key, prop := range embedded.Properties {
+				s.Properties[key] = prop
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		} else if sf.PkgPath != "" {
+			continue
+		} else if name == "" {
+			name = sf.Name
+		}
+
+		prop, required := o.fieldSchema(sf)
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
+
+// fieldSchema returns the schema for a struct field with the constraints
+// from the validate tag and the value from the default tag. True is returned
+// if the field is required.
+func (o *OpenAPI) fieldSchema(sf reflect.StructField) (*Schema, bool) {
+	s := o.schemaOf(sf.Type)
+	required := applyRules(s, sf.Type, strings.Split(sf.Tag.Get("validate"), ","))
+
+	if def, ok := sf.Tag.Lookup("default"); ok {
+		s.Default = schemaValue(sf.Type, def)
+	}
+
+	return s, required
+}
+
+// applyRules adds the constraints of the validation rules to the schema. The
+// rules after dive are added to the schema of the items. True is returned if
+// the required rule is found.
+func applyRules(s *Schema, t reflect.Type, rules []string) (required bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for i, rule := range rules {
+		tag, param := rule, ""
+		if j := strings.Index(rule, "="); j >= 0 {
+			tag, param = rule[:j], rule[j+1:]
+		}
+
+		switch tag {
+		case "required":
+			required = true
+		case "dive":
+			if s.Items != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				applyRules(s.Items, t.Elem(), rules[i+1:])
+			} else if s.AdditionalProperties != nil && t.Kind() == reflect.Map {
+				applyRules(s.AdditionalProperties, t.Elem(), rules[i+1:])
+			}
+			return
+		case "min", "gte":
+			setLimit(s, t, param, &s.MinLength, &s.MinItems, &s.Minimum)
+		case "max", "lte":
+			setLimit(s, t, param, &s.MaxLength, &s.MaxItems, &s.Maximum)
+		case "len":
+			setLimit(s, t, param, &s.MinLength, &s.MinItems, &s.Minimum)
+			setLimit(s, t, param, &s.MaxLength, &s.MaxItems, &s.Maximum)
+		case "gt":
+			setLimit(s, t, param, nil, nil, &s.ExclusiveMinimum)
+		case "lt":
+			setLimit(s, t, param, nil, nil, &s.ExclusiveMaximum)
+		case "oneof":
+			for _, val := range strings.Fields(param) {
+				s.Enum = append(s.Enum, schemaValue(t, val))
+			}
+		default:
+			if format, ok := schemaFormats[tag]; ok {
+				s.Format = format
+			} else if pattern, ok := schemaPatterns[tag]; ok {
+				s.Pattern = pattern
+			}
+		}
+	}
+
+	return
+}
+
+// setLimit sets the length of a string, the number of items of a list, or
+// the value of a number depending on the type.
+func setLimit(s *Schema, t reflect.Type, param string, length, items **int64, number **float64) {
+	switch t.Kind() {
+	case reflect.String:
+		if n, err := strconv.ParseInt(param, 10, 64); err == nil && length != nil {
+			*length = &n
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if n, err := strconv.ParseInt(param, 10, 64); err == nil && items != nil {
+			*items = &n
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(param, 64); err == nil {
+			*number = &f
+		}
+	}
+}
+
+// schemaValue converts a value from a tag to the type of the field so a
+// number is shown as a number. The values of a slice are separated by
+// commas.
+func schemaValue(t reflect.Type, val string) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			arr := make([]interface{}, 0)
+			for _, v := range strings.Split(val, ",") {
+				arr = append(arr, schemaValue(t.Elem(), v))
+			}
+			return arr
+		}
+	}
+
+	return val
+}
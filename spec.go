@@ -0,0 +1,735 @@
+package octane
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// specMethods are the operations of a path item.
+var specMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// Spec is an OpenAPI 3 or a Swagger 2.0 document that is used to validate
+// the requests before the handler runs and the responses after.
+type Spec struct {
+	operations []*specOperation
+	refs       map[string]*Schema
+}
+
+// specOperation contains the parts of an operation that are validated.
+type specOperation struct {
+	method    string
+	segments  []string
+	literals  int
+	params    []specParam
+	body      *specBody
+	responses map[string]*Schema
+}
+
+// specParam is a path, query, header, or cookie parameter.
+type specParam struct {
+	name     string
+	in       string
+	required bool
+	schema   *Schema
+}
+
+// specBody contains the schema of the body for each media type.
+type specBody struct {
+	required bool
+	media    map[string]*Schema
+}
+
+// specDocument contains the members of an OpenAPI 3 and a Swagger 2.0
+// document that are needed to validate the requests and the responses.
+type specDocument struct {
+	Swagger     string                                `json:"swagger"`
+	BasePath    string                                `json:"basePath"`
+	Consumes    []string                              `json:"consumes"`
+	Paths       map[string]map[string]json.RawMessage `json:"paths"`
+	Definitions map[string]*Schema                    `json:"definitions"`
+	Parameters  map[string]json.RawMessage            `json:"parameters"`
+	Responses   map[string]specResponseDoc            `json:"responses"`
+	Components  struct {
+		Schemas       map[string]*Schema            `json:"schemas"`
+		Parameters    map[string]json.RawMessage    `json:"parameters"`
+		Responses     map[string]specResponseDoc    `json:"responses"`
+		RequestBodies map[string]specRequestBodyDoc `json:"requestBodies"`
+	} `json:"components"`
+}
+
+// specOperationDoc is an operation in the document.
+type specOperationDoc struct {
+	Consumes    []string                   `json:"consumes"`
+	Parameters  []json.RawMessage          `json:"parameters"`
+	RequestBody *specRequestBodyDoc        `json:"requestBody"`
+	Responses   map[string]specResponseDoc `json:"responses"`
+}
+
+// specParamDoc is a parameter in the document. A Swagger 2.0 parameter has
+// the members of the schema instead of a schema.
+type specParamDoc struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// specRequestBodyDoc is an OpenAPI 3 request body in the document.
+type specRequestBodyDoc struct {
+	Ref      string                  `json:"$ref"`
+	Required bool                    `json:"required"`
+	Content  map[string]specMediaDoc `json:"content"`
+}
+
+// specResponseDoc is a response in the document. A Swagger 2.0 response has
+// a schema instead of the content.
+type specResponseDoc struct {
+	Ref     string                  `json:"$ref"`
+	Schema  *Schema                 `json:"schema"`
+	Content map[string]specMediaDoc `json:"content"`
+}
+
+// specMediaDoc contains the schema for a media type.
+type specMediaDoc struct {
+	Schema *Schema `json:"schema"`
+}
+
+// NewSpec reads an OpenAPI 3 or a Swagger 2.0 document in JSON like the
+// swagger.json from go-swagger or the document from OpenAPI.MarshalJSON.
+func NewSpec(doc []byte) (*Spec, error) {
+	d := new(specDocument)
+	if err := json.Unmarshal(doc, d); err != nil {
+		return nil, fmt.Errorf("octane: could not read the spec: %v", err)
+	}
+
+	s := &Spec{refs: make(map[string]*Schema)}
+	for name, schema := range d.Definitions {
+		s.refs["#/definitions/"+name] = schema
+	}
+	for name, schema := range d.Components.Schemas {
+		s.refs["#/components/schemas/"+name] = schema
+	}
+
+	basePath := strings.TrimSuffix(d.BasePath, "/")
+	for path, item := range d.Paths {
+		var shared []json.RawMessage
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("octane: could not read the parameters of %v: %v", path, err)
+			}
+		}
+
+		for _, method := range specMethods {
+			raw, ok := item[strings.ToLower(method)]
+			if !ok {
+				continue
+			}
+
+			od := new(specOperationDoc)
+			if err := json.Unmarshal(raw, od); err != nil {
+				return nil, fmt.Errorf("octane: could not read %v %v: %v", method, path, err)
+			}
+
+			params := append(append([]json.RawMessage{}, shared...), od.Parameters...)
+			op, err := d.operation(method, basePath+path, params, od)
+			if err != nil {
+				return nil, fmt.Errorf("octane: could not read %v %v: %v", method, path, err)
+			}
+			s.operations = append(s.operations, op)
+		}
+	}
+
+	// Match the paths with the most literal segments first so /note/new is
+	// used before /note/{note_id}.
+	sort.SliceStable(s.operations, func(i, j int) bool {
+		return s.operations[i].literals > s.operations[j].literals
+	})
+
+	return s, nil
+}
+
+// operation returns the parts of an operation that are validated.
+func (d *specDocument) operation(method string, path string, params []json.RawMessage, od *specOperationDoc) (*specOperation, error) {
+	op := &specOperation{
+		method:    method,
+		segments:  strings.Split(path, "/"),
+		responses: make(map[string]*Schema),
+	}
+
+	for _, seg := range op.segments {
+		if !strings.HasPrefix(seg, "{") {
+			op.literals++
+		}
+	}
+
+	form := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, raw := range params {
+		p, err := d.parameter(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		switch p.in {
+		case InBody:
+			op.body = &specBody{required: p.required, media: make(map[string]*Schema)}
+			consumes := od.Consumes
+			if len(consumes) == 0 {
+				consumes = d.Consumes
+			}
+			for _, mt := range consumes {
+				op.body.media[mt] = p.schema
+			}
+			op.body.media[echo.MIMEApplicationJSON] = p.schema
+		case InFormData:
+			form.Properties[p.name] = p.schema
+			if p.required {
+				form.Required = append(form.Required, p.name)
+			}
+		default:
+			// Replace a path level parameter with the operation parameter.
+			for i := range op.params {
+				if op.params[i].name == p.name && op.params[i].in == p.in {
+					op.params = append(op.params[:i], op.params[i+1:]...)
+					break
+				}
+			}
+			op.params = append(op.params, p)
+		}
+	}
+
+	if len(form.Properties) > 0 {
+		op.body = &specBody{media: map[string]*Schema{
+			echo.MIMEApplicationForm: form,
+		}}
+	}
+
+	if rb := od.RequestBody; rb != nil {
+		if strings.HasPrefix(rb.Ref, "#/components/requestBodies/") {
+			named := d.Components.RequestBodies[strings.TrimPrefix(rb.Ref, "#/components/requestBodies/")]
+			rb = &named
+		}
+		op.body = &specBody{required: rb.Required, media: make(map[string]*Schema)}
+		for mt, m := range rb.Content {
+			op.body.media[mt] = m.Schema
+		}
+	}
+
+	for code, resp := range od.Responses {
+		switch {
+		case strings.HasPrefix(resp.Ref, "#/responses/"):
+			resp = d.Responses[strings.TrimPrefix(resp.Ref, "#/responses/")]
+		case strings.HasPrefix(resp.Ref, "#/components/responses/"):
+			resp = d.Components.Responses[strings.TrimPrefix(resp.Ref, "#/components/responses/")]
+		}
+
+		op.responses[code] = resp.Schema
+		for mt, m := range resp.Content {
+			if isJSONMediaType(mt) {
+				op.responses[code] = m.Schema
+			}
+		}
+	}
+
+	return op, nil
+}
+
+// parameter returns a parameter of an operation. The members of a Swagger
+// 2.0 parameter are read as the schema.
+func (d *specDocument) parameter(raw json.RawMessage) (specParam, error) {
+	pd := new(specParamDoc)
+	if err := json.Unmarshal(raw, pd); err != nil {
+		return specParam{}, err
+	}
+
+	if len(pd.Ref) > 0 {
+		named, ok := d.Parameters[strings.TrimPrefix(pd.Ref, "#/parameters/")]
+		if !ok {
+			named, ok = d.Components.Parameters[strings.TrimPrefix(pd.Ref, "#/components/parameters/")]
+		}
+		if !ok {
+			return specParam{}, fmt.Errorf("parameter %v is not defined", pd.Ref)
+		}
+		return d.parameter(named)
+	}
+
+	if pd.Schema == nil {
+		members := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(raw, &members); err != nil {
+			return specParam{}, err
+		}
+		delete(members, "name")
+		delete(members, "in")
+		delete(members, "required")
+
+		b, err := json.Marshal(members)
+		if err != nil {
+			return specParam{}, err
+		}
+		pd.Schema = new(Schema)
+		if err = json.Unmarshal(b, pd.Schema); err != nil {
+			return specParam{}, err
+		}
+	}
+
+	return specParam{
+		name:     pd.Name,
+		in:       pd.In,
+		required: pd.Required || pd.In == InPath,
+		schema:   pd.Schema,
+	}, nil
+}
+
+// find returns the operation for the request and the values of the path
+// parameters.
+func (s *Spec) find(r *http.Request) (*specOperation, map[string]string) {
+	segments := strings.Split(r.URL.EscapedPath(), "/")
+	for _, op := range s.operations {
+		if op.method != r.Method || len(op.segments) != len(segments) {
+			continue
+		}
+
+		params := make(map[string]string)
+		for i, seg := range op.segments {
+			if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+				val, err := url.PathUnescape(segments[i])
+				if err != nil {
+					val = segments[i]
+				}
+				params[seg[1:len(seg)-1]] = val
+			} else if seg != segments[i] {
+				params = nil
+				break
+			}
+		}
+
+		if params != nil {
+			return op, params
+		}
+	}
+
+	return nil, nil
+}
+
+// defaultSpecMaxBodySize is the max number of bytes of a body the Spec
+// middleware reads unless another size is set.
+const defaultSpecMaxBodySize = 32 << 20
+
+// SpecOption is an option for the Spec middleware.
+type SpecOption func(*specMiddleware)
+
+// specMiddleware contains the options for the Spec middleware.
+type specMiddleware struct {
+	spec        *Spec
+	responses   bool
+	maxBodySize int64
+}
+
+// WithSpecMaxBodySize sets the max number of bytes of a body the Spec
+// middleware reads to validate it. A larger body returns 413 Request Entity
+// Too Large. The default is 32 MB and a size set by the MaxBodySize
+// middleware before the Spec middleware takes precedence.
+func WithSpecMaxBodySize(size int64) SpecOption {
+	return func(m *specMiddleware) {
+		m.maxBodySize = size
+	}
+}
+
+// WithResponseValidation will validate the status code and the JSON body of
+// each response against the document. A response that doesn't match is
+// replaced with a 500 that lists each failed field. It buffers every
+// response so it's meant to find the differences between the code and the
+// document in tests.
+func WithResponseValidation() SpecOption {
+	return func(m *specMiddleware) {
+		m.responses = true
+	}
+}
+
+// Middleware returns an echo.MiddlewareFunc that validates the path
+// parameters, query string, headers, cookies, and the body of each request
+// described by the document before the handler runs. The failed fields are
+// sent the same way as the errors from the Binder using BindErrorResponse.
+// Requests that aren't in the document are passed to the handler. The body
+// is read into memory up to the size of WithSpecMaxBodySize.
+func (s *Spec) Middleware(opts ...SpecOption) echo.MiddlewareFunc {
+	m := &specMiddleware{spec: s, maxBodySize: defaultSpecMaxBodySize}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			op, params := s.find(c.Request())
+			if op == nil {
+				return next(c)
+			}
+
+			limit := m.maxBodySize
+			if size, ok := c.Request().Context().Value(keyMaxBodySize).(int64); ok {
+				limit = size
+			}
+
+			if err := s.validateRequest(op, c.Request(), params, limit); err != nil {
+				rc := &ResponseJSON{Context: c}
+				return rc.BindErrorResponse(err)
+			}
+
+			if !m.responses {
+				return next(c)
+			}
+
+			return s.validateResponse(op, c, next)
+		}
+	}
+}
+
+// validateRequest returns a ValidationError with each parameter and each
+// field of the body that doesn't match the document, a SyntaxError if the
+// JSON body is malformed, or a BodyTooLargeError if the body is larger than
+// the limit.
+func (s *Spec) validateRequest(op *specOperation, r *http.Request, params map[string]string, limit int64) error {
+	errs := make([]FieldError, 0)
+	query := r.URL.Query()
+	for _, p := range op.params {
+		var vals []string
+		switch p.in {
+		case InPath:
+			vals = []string{params[p.name]}
+		case InQuery:
+			vals = query[p.name]
+		case InHeader:
+			vals = s.headerValues(p.schema, r.Header.Values(p.name))
+		case InCookie:
+			if cookie, err := r.Cookie(p.name); err == nil {
+				vals = s.headerValues(p.schema, []string{cookie.Value})
+			}
+		}
+
+		errs = append(errs, s.validateStrings(p.name, vals, p.required, p.schema)...)
+	}
+
+	bodyErrs, err := s.validateBody(op, r, limit)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, bodyErrs...)
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// validateBody returns each field of the body that doesn't match the
+// document. A JSON body and a form body are validated. The body is read
+// into memory up to the limit and replaced so the handler can read it again.
+// A compressed body is left for the Binder.
+func (s *Spec) validateBody(op *specOperation, r *http.Request, limit int64) ([]FieldError, error) {
+	if op.body == nil || r.Body == nil || r.Body == http.NoBody {
+		if op.body != nil && op.body.required {
+			return []FieldError{requiredError("body")}, nil
+		}
+		return nil, nil
+	}
+
+	enc := r.Header.Get(echo.HeaderContentEncoding)
+	if len(enc) > 0 && !strings.EqualFold(enc, "identity") {
+		return nil, nil
+	}
+
+	var body io.Reader = r.Body
+	if limit > 0 {
+		body = &limitReader{r: body, limit: limit}
+	}
+
+	b, err := io.ReadAll(body)
+	if be := bodyError(err); be != nil {
+		return nil, be
+	} else if err != nil {
+		return nil, err
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(b))
+
+	if len(b) == 0 {
+		if op.body.required {
+			return []FieldError{requiredError("body")}, nil
+		}
+		return nil, nil
+	}
+
+	mt, _, _ := mime.ParseMediaType(r.Header.Get(echo.HeaderContentType))
+	schema, ok := op.body.media[mt]
+	if !ok && isJSONMediaType(mt) {
+		schema, ok = op.body.media[echo.MIMEApplicationJSON]
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	if mt == echo.MIMEApplicationForm {
+		form, err := url.ParseQuery(string(b))
+		if err != nil {
+			return nil, &SyntaxError{Err: err}
+		}
+		return s.validateForm(form, schema), nil
+	} else if !isJSONMediaType(mt) {
+		return nil, nil
+	}
+
+	v, err := decodeSpecJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.validateValue("", v, schema), nil
+}
+
+// validateForm returns each member of a form body that doesn't match the
+// properties of the schema.
+func (s *Spec) validateForm(form url.Values, schema *Schema) []FieldError {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+
+	errs := make([]FieldError, 0)
+	for _, name := range sortedKeys(schema.Properties) {
+		required := false
+		for _, req := range schema.Required {
+			required = required || req == name
+		}
+		errs = append(errs, s.validateStrings(name, form[name], required, schema.Properties[name])...)
+	}
+
+	return errs
+}
+
+// headerValues splits the values of an array header or cookie on commas
+// like the Binder. An HTTP date contains a comma so an array of times is not
+// split.
+func (s *Spec) headerValues(schema *Schema, vals []string) []string {
+	schema = s.resolve(schema)
+	if schema == nil || schema.Type != "array" {
+		return vals
+	} else if items := s.resolve(schema.Items); items != nil && items.Format == "date-time" {
+		return vals
+	}
+
+	values := make([]string, 0, len(vals))
+	for _, val := range vals {
+		for _, part := range strings.Split(val, ",") {
+			if part = strings.TrimSpace(part); len(part) > 0 {
+				values = append(values, part)
+			}
+		}
+	}
+
+	return values
+}
+
+// validateStrings converts the values of a parameter to the type of the
+// schema and returns each value that doesn't match. Like the Binder, each
+// value is an item of an array so the values of a query string or a form
+// array are repeated.
+func (s *Spec) validateStrings(name string, vals []string, required bool, schema *Schema) []FieldError {
+	if len(vals) == 0 {
+		if required {
+			return []FieldError{requiredError(name)}
+		}
+		return nil
+	}
+
+	schema = s.resolve(schema)
+	if schema == nil {
+		return nil
+	} else if schema.Type != "array" {
+		v, fe := stringValue(name, vals[0], schema.Type)
+		if fe != nil {
+			return []FieldError{*fe}
+		}
+		return s.validateValue(name, v, schema)
+	}
+
+	items := s.resolve(schema.Items)
+	arr := make([]interface{}, 0, len(vals))
+	errs := make([]FieldError, 0)
+	for i, val := range vals {
+		itemType := ""
+		if items != nil {
+			itemType = items.Type
+		}
+
+		v, fe := stringValue(fmt.Sprintf("%s[%d]", name, i), val, itemType)
+		if fe != nil {
+			errs = append(errs, *fe)
+			continue
+		}
+		arr = append(arr, v)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return s.validateValue(name, arr, schema)
+}
+
+// validateResponse calls the handler with a buffered response and checks
+// the status code and the JSON body before it's sent.
+func (s *Spec) validateResponse(op *specOperation, c echo.Context, next echo.HandlerFunc) error {
+	res := c.Response()
+	w := res.Writer
+	bw := &bufferedWriter{ResponseWriter: w}
+	res.Writer = bw
+
+	err := next(c)
+	res.Writer = w
+	if err != nil || bw.code == 0 {
+		// The error handler sends the response.
+		bw.flush()
+		return err
+	}
+
+	errs := s.responseErrors(op, bw, res.Header())
+	if len(errs) == 0 {
+		bw.flush()
+		return nil
+	}
+
+	resp := new(ValidationErrorResponse)
+	resp.Body.Message = "the response failed validation"
+	resp.Body.StatusCode = http.StatusInternalServerError
+	resp.Body.StatusMessage = http.StatusText(resp.Body.StatusCode)
+	resp.Body.Errors = errs
+
+	b, jerr := json.Marshal(resp.Body)
+	if jerr != nil {
+		return jerr
+	}
+
+	res.Status = resp.Body.StatusCode
+	res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	res.Header().Del(echo.HeaderContentLength)
+	w.WriteHeader(resp.Body.StatusCode)
+	w.Write(b)
+
+	ve := &ValidationError{Errors: errs}
+	return fmt.Errorf("octane: the response of %v %v failed validation: %w",
+		c.Request().Method, c.Request().URL.Path, ve)
+}
+
+// responseErrors returns an error if the status code isn't in the document
+// or each field of the JSON body that doesn't match the schema. A 304 Not
+// Modified for a conditional request doesn't need to be in the document.
+func (s *Spec) responseErrors(op *specOperation, bw *bufferedWriter, header http.Header) []FieldError {
+	if len(op.responses) == 0 || bw.code == http.StatusNotModified {
+		return nil
+	}
+
+	code := fmt.Sprint(bw.code)
+	schema, ok := op.responses[code]
+	if !ok {
+		schema, ok = op.responses[code[:1]+"XX"]
+	}
+	if !ok {
+		schema, ok = op.responses["default"]
+	}
+	if !ok {
+		codes := strings.Join(sortedKeys(op.responses), " ")
+		return []FieldError{{
+			Field:   "status_code",
+			Rule:    "oneof",
+			Param:   codes,
+			Message: fmt.Sprintf("status_code must be one of [%s]", codes),
+		}}
+	}
+
+	mt, _, _ := mime.ParseMediaType(header.Get(echo.HeaderContentType))
+	if schema == nil || bw.buf.Len() == 0 || !isJSONMediaType(mt) {
+		return nil
+	}
+
+	v, err := decodeSpecJSON(bw.buf.Bytes())
+	if err != nil {
+		return []FieldError{{
+			Field:   "body",
+			Rule:    "json",
+			Message: err.Error(),
+		}}
+	}
+
+	return s.validateValue("", v, schema)
+}
+
+// bufferedWriter keeps the response in memory so it can be replaced.
+type bufferedWriter struct {
+	http.ResponseWriter
+	code int
+	buf  bytes.Buffer
+}
+
+// WriteHeader stores the status code.
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// Write stores the body.
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.buf.Write(b)
+}
+
+// flush sends the stored response.
+func (w *bufferedWriter) flush() {
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+		w.ResponseWriter.Write(w.buf.Bytes())
+	}
+}
+
+// decodeSpecJSON decodes JSON with the numbers kept as strings so large
+// integers are compared without rounding.
+func decodeSpecJSON(b []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err := dec.Decode(&v)
+
+	var se *json.SyntaxError
+	switch {
+	case errors.As(err, &se):
+		return nil, &SyntaxError{Offset: se.Offset, Err: err}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return nil, &SyntaxError{Offset: int64(len(b)), Err: err}
+	case err != nil:
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// isJSONMediaType returns true for application/json and media types with a
+// +json suffix.
+func isJSONMediaType(mt string) bool {
+	return mt == echo.MIMEApplicationJSON || strings.HasSuffix(mt, "+json")
+}
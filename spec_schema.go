@@ -0,0 +1,346 @@
+package octane
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// specPatterns contains the compiled patterns of the schemas.
+var specPatterns sync.Map
+
+// specFormats checks the formats of the strings. A format that isn't in the
+// map is not checked.
+var specFormats = map[string]struct {
+	name  string
+	valid func(s string) bool
+}{
+	"email": {"email address", func(s string) bool {
+		a, err := mail.ParseAddress(s)
+		return err == nil && a.Address == s
+	}},
+	"uuid": {"UUID", func(s string) bool {
+		return uuidPattern.MatchString(s)
+	}},
+	"uri": {"URI", func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && len(u.Scheme) > 0
+	}},
+	"date-time": {"date and time", func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	}},
+	"date": {"date", func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	}},
+	"ipv4": {"IPv4 address", func(s string) bool {
+		a, err := netip.ParseAddr(s)
+		return err == nil && a.Is4()
+	}},
+	"ipv6": {"IPv6 address", func(s string) bool {
+		a, err := netip.ParseAddr(s)
+		return err == nil && a.Is6()
+	}},
+}
+
+// uuidPattern matches a UUID in the canonical format.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// resolve returns the schema for a reference. A reference that isn't in the
+// document returns nil so the value is not checked.
+func (s *Spec) resolve(schema *Schema) *Schema {
+	for i := 0; schema != nil && len(schema.Ref) > 0; i++ {
+		if i > 32 {
+			return nil
+		}
+		schema = s.refs[schema.Ref]
+	}
+
+	return schema
+}
+
+// validateValue returns each part of a decoded JSON value that doesn't match
+// the schema. The field is the path to the value using the JSON names, like
+// "items[0].name", and is empty for the entire body.
+func (s *Spec) validateValue(field string, v interface{}, schema *Schema) []FieldError {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+
+	errs := make([]FieldError, 0)
+	for _, sub := range schema.AllOf {
+		errs = append(errs, s.validateValue(field, v, sub)...)
+	}
+
+	if v == nil {
+		if schema.nullable || len(schema.Type) == 0 {
+			return errs
+		}
+		return append(errs, typeError(field, schema.Type))
+	}
+
+	switch schema.Type {
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return append(errs, typeError(field, schema.Type))
+		}
+		errs = append(errs, s.validateObject(field, m, schema)...)
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return append(errs, typeError(field, schema.Type))
+		}
+		errs = append(errs, countErrors(field, int64(len(arr)), "items", schema.MinItems, schema.MaxItems)...)
+		for i, item := range arr {
+			errs = append(errs, s.validateValue(fmt.Sprintf("%s[%d]", field, i), item, schema.Items)...)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return append(errs, typeError(field, schema.Type))
+		}
+		errs = append(errs, stringErrors(field, str, schema)...)
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			return append(errs, typeError(field, schema.Type))
+		}
+		f, err := n.Float64()
+		if err != nil || (schema.Type == "integer" && f != math.Trunc(f)) {
+			return append(errs, typeError(field, schema.Type))
+		}
+		errs = append(errs, numberErrors(field, f, schema)...)
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return append(errs, typeError(field, schema.Type))
+		}
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(v, schema.Enum) {
+		vals := make([]string, 0, len(schema.Enum))
+		for _, e := range schema.Enum {
+			vals = append(vals, fmt.Sprint(e))
+		}
+		param := strings.Join(vals, " ")
+		errs = append(errs, FieldError{
+			Field:   fieldName(field),
+			Rule:    "oneof",
+			Param:   param,
+			Message: fmt.Sprintf("%s must be one of [%s]", fieldName(field), param),
+		})
+	}
+
+	return errs
+}
+
+// validateObject returns each required member that is missing and each
+// member that doesn't match its schema.
+func (s *Spec) validateObject(field string, m map[string]interface{}, schema *Schema) []FieldError {
+	errs := make([]FieldError, 0)
+	for _, name := range schema.Required {
+		if _, ok := m[name]; !ok {
+			errs = append(errs, requiredError(joinField(field, name)))
+		}
+	}
+
+	for _, name := range sortedKeys(m) {
+		if prop, ok := schema.Properties[name]; ok {
+			errs = append(errs, s.validateValue(joinField(field, name), m[name], prop)...)
+		} else if schema.AdditionalProperties != nil {
+			errs = append(errs, s.validateValue(joinField(field, name), m[name], schema.AdditionalProperties)...)
+		}
+	}
+
+	return errs
+}
+
+// stringErrors returns an error for each length, pattern, and format
+// constraint that the string doesn't match.
+func stringErrors(field string, str string, schema *Schema) []FieldError {
+	errs := countErrors(field, int64(utf8.RuneCountInString(str)), "characters", schema.MinLength, schema.MaxLength)
+
+	if len(schema.Pattern) > 0 {
+		re, ok := specPatterns.Load(schema.Pattern)
+		if !ok {
+			compiled, err := regexp.Compile(schema.Pattern)
+			if err == nil {
+				re, _ = specPatterns.LoadOrStore(schema.Pattern, compiled)
+			}
+		}
+		if re != nil && !re.(*regexp.Regexp).MatchString(str) {
+			errs = append(errs, FieldError{
+				Field:   fieldName(field),
+				Rule:    "pattern",
+				Param:   schema.Pattern,
+				Message: fmt.Sprintf("%s must match the pattern %s", fieldName(field), schema.Pattern),
+			})
+		}
+	}
+
+	if format, ok := specFormats[schema.Format]; ok && !format.valid(str) {
+		errs = append(errs, FieldError{
+			Field:   fieldName(field),
+			Rule:    schema.Format,
+			Message: fmt.Sprintf("%s must be a valid %s", fieldName(field), format.name),
+		})
+	}
+
+	return errs
+}
+
+// numberErrors returns an error for each limit that the number is outside
+// of.
+func numberErrors(field string, f float64, schema *Schema) []FieldError {
+	name := fieldName(field)
+	errs := make([]FieldError, 0)
+	limits := []struct {
+		limit   *float64
+		failed  func(limit float64) bool
+		rule    string
+		message string
+	}{
+		{schema.Minimum, func(l float64) bool { return f < l }, "min", "%s must be at least %s"},
+		{schema.Maximum, func(l float64) bool { return f > l }, "max", "%s must be at most %s"},
+		{schema.ExclusiveMinimum, func(l float64) bool { return f <= l }, "gt", "%s must be greater than %s"},
+		{schema.ExclusiveMaximum, func(l float64) bool { return f >= l }, "lt", "%s must be less than %s"},
+	}
+
+	for _, l := range limits {
+		if l.limit != nil && l.failed(*l.limit) {
+			param := strconv.FormatFloat(*l.limit, 'f', -1, 64)
+			errs = append(errs, FieldError{
+				Field:   name,
+				Rule:    l.rule,
+				Param:   param,
+				Message: fmt.Sprintf(l.message, name, param),
+			})
+		}
+	}
+
+	return errs
+}
+
+// countErrors returns an error if the length of a string or an array is
+// outside of the limits.
+func countErrors(field string, n int64, unit string, min *int64, max *int64) []FieldError {
+	name := fieldName(field)
+	switch {
+	case min != nil && n < *min:
+		return []FieldError{{
+			Field:   name,
+			Rule:    "min",
+			Param:   strconv.FormatInt(*min, 10),
+			Message: fmt.Sprintf("%s must be at least %d %s", name, *min, unit),
+		}}
+	case max != nil && n > *max:
+		return []FieldError{{
+			Field:   name,
+			Rule:    "max",
+			Param:   strconv.FormatInt(*max, 10),
+			Message: fmt.Sprintf("%s must be at most %d %s", name, *max, unit),
+		}}
+	}
+
+	return nil
+}
+
+// stringValue converts the value of a parameter to the JSON type of the
+// schema.
+func stringValue(field string, val string, typ string) (interface{}, *FieldError) {
+	switch typ {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(val, 64); err != nil {
+			fe := typeError(field, typ)
+			return nil, &fe
+		}
+		return json.Number(val), nil
+	case "boolean":
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			fe := typeError(field, typ)
+			return nil, &fe
+		}
+		return b, nil
+	}
+
+	return val, nil
+}
+
+// inEnum returns true if the value is one of the values of the enum.
+func inEnum(v interface{}, enum []interface{}) bool {
+	if n, ok := v.(json.Number); ok {
+		f, _ := n.Float64()
+		v = f
+	}
+
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeError returns the error for a value with the wrong JSON type.
+func typeError(field string, typ string) FieldError {
+	return FieldError{
+		Field:   fieldName(field),
+		Rule:    "type",
+		Param:   typ,
+		Message: fmt.Sprintf("%s must be of type %s", fieldName(field), typ),
+	}
+}
+
+// requiredError returns the error for a missing value.
+func requiredError(field string) FieldError {
+	return FieldError{
+		Field:   field,
+		Rule:    "required",
+		Message: fmt.Sprintf("%s is required", field),
+	}
+}
+
+// joinField returns the path to a member of an object.
+func joinField(field string, name string) string {
+	if len(field) == 0 {
+		return name
+	}
+
+	return field + "." + name
+}
+
+// fieldName returns the name used in the errors for a path. The entire body
+// has an empty path.
+func fieldName(field string) string {
+	if len(field) == 0 {
+		return "body"
+	}
+
+	return field
+}
+
+// sortedKeys returns the keys of a map in order so the errors are always in
+// the same order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
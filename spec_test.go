@@ -0,0 +1,278 @@
+package octane_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// swaggerSpec is a Swagger 2.0 document like the one from go-swagger.
+const swaggerSpec = `{
+  "swagger": "2.0",
+  "basePath": "/",
+  "consumes": ["application/json"],
+  "paths": {
+    "/note/{note_id}": {
+      "parameters": [
+        {"name": "note_id", "in": "path", "required": true, "type": "string", "format": "uuid"}
+      ],
+      "put": {
+        "parameters": [
+          {"name": "X-Version", "in": "header", "type": "integer", "minimum": 1},
+          {"name": "tags", "in": "query", "type": "array", "maxItems": 2, "items": {"type": "string", "enum": ["red", "blue"]}},
+          {"name": "Body", "in": "body", "required": true, "schema": {
+            "type": "object",
+            "required": ["message"],
+            "properties": {
+              "message": {"type": "string", "minLength": 2},
+              "priority": {"type": "integer", "maximum": 5, "exclusiveMaximum": true},
+              "author": {"$ref": "#/definitions/Author"}
+            }
+          }}
+        ],
+        "responses": {
+          "200": {"$ref": "#/responses/NoteResponse"},
+          "204": {"description": "No Content"}
+        }
+      }
+    },
+    "/note/new": {
+      "post": {
+        "parameters": [
+          {"name": "message", "in": "formData", "type": "string", "required": true},
+          {"name": "count", "in": "formData", "type": "integer"}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  },
+  "definitions": {
+    "Author": {
+      "type": "object",
+      "required": ["email"],
+      "properties": {"email": {"type": "string", "format": "email"}}
+    }
+  },
+  "responses": {
+    "NoteResponse": {
+      "schema": {
+        "type": "object",
+        "required": ["status_code", "data"],
+        "properties": {
+          "status_code": {"type": "integer"},
+          "data": {"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}}}
+        }
+      }
+    }
+  }
+}`
+
+const specNoteID = "314445cd-e9fb-4c58-58b6-777ee06465f5"
+
+// specServer returns echo with the spec middleware and a handler that sends
+// the body of the request back.
+func specServer(t *testing.T, doc string, opts ...octane.SpecOption) *echo.Echo {
+	spec, err := octane.NewSpec([]byte(doc))
+	assert.Nil(t, err)
+
+	e := echo.New()
+	e.Binder = octane.NewBinder()
+	e.Use(spec.Middleware(opts...))
+	e.PUT("/note/:note_id", func(c echo.Context) error {
+		req := new(struct {
+			Message string `json:"message"`
+		})
+		if err := c.Bind(req); err != nil {
+			return err
+		}
+
+		switch req.Message {
+		case "empty":
+			return c.NoContent(http.StatusNoContent)
+		case "teapot":
+			return c.NoContent(http.StatusTeapot)
+		case "unchanged":
+			return c.NoContent(http.StatusNotModified)
+		case "missing":
+			return c.JSON(http.StatusOK, map[string]interface{}{"status_code": "200"})
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"status_code": 200,
+			"data":        map[string]string{"id": req.Message},
+		})
+	})
+	e.POST("/note/new", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+	e.GET("/healthcheck", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	return e
+}
+
+func TestSpecRequest(t *testing.T) {
+	e := specServer(t, swaggerSpec)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		header      string
+		body        string
+		code        int
+		want        string
+	}{
+		{"valid", http.MethodPut, "/note/" + specNoteID + "?tags=red&tags=blue", echo.MIMEApplicationJSON, "2",
+			`{"message":"hello","priority":4,"author":{"email":"jsmith@example.com"}}`, http.StatusOK, ""},
+		{"not in the spec", http.MethodGet, "/healthcheck", "", "", "", http.StatusOK, ""},
+		{"parameters", http.MethodPut, "/note/10?tags=red&tags=green&tags=blue", echo.MIMEApplicationJSON, "0",
+			`{"message":"hello"}`, http.StatusUnprocessableEntity,
+			`{"field":"note_id","rule":"uuid","message":"note_id must be a valid UUID"},` +
+				`{"field":"X-Version","rule":"min","param":"1","message":"X-Version must be at least 1"},` +
+				`{"field":"tags","rule":"max","param":"2","message":"tags must be at most 2 items"},` +
+				`{"field":"tags[1]","rule":"oneof","param":"red blue","message":"tags[1] must be one of [red blue]"}`},
+		{"comma in a query value", http.MethodPut, "/note/" + specNoteID + "?tags=red,blue", echo.MIMEApplicationJSON, "",
+			`{"message":"hello"}`, http.StatusUnprocessableEntity,
+			`{"field":"tags[0]","rule":"oneof","param":"red blue","message":"tags[0] must be one of [red blue]"}`},
+		{"parameter type", http.MethodPut, "/note/" + specNoteID, echo.MIMEApplicationJSON, "one",
+			`{"message":"hello"}`, http.StatusUnprocessableEntity,
+			`{"field":"X-Version","rule":"type","param":"integer","message":"X-Version must be of type integer"}`},
+		{"body", http.MethodPut, "/note/" + specNoteID, echo.MIMEApplicationJSON, "",
+			`{"priority":5,"author":{"email":"jsmith"}}`, http.StatusUnprocessableEntity,
+			`{"field":"message","rule":"required","message":"message is required"},` +
+				`{"field":"author.email","rule":"email","message":"author.email must be a valid email address"},` +
+				`{"field":"priority","rule":"lt","param":"5","message":"priority must be less than 5"}`},
+		{"body type", http.MethodPut, "/note/" + specNoteID, echo.MIMEApplicationJSON, "",
+			`{"message":"a","priority":1.5}`, http.StatusUnprocessableEntity,
+			`{"field":"message","rule":"min","param":"2","message":"message must be at least 2 characters"},` +
+				`{"field":"priority","rule":"type","param":"integer","message":"priority must be of type integer"}`},
+		{"missing body", http.MethodPut, "/note/" + specNoteID, echo.MIMEApplicationJSON, "", ``,
+			http.StatusUnprocessableEntity,
+			`{"field":"body","rule":"required","message":"body is required"}`},
+		{"malformed body", http.MethodPut, "/note/" + specNoteID, echo.MIMEApplicationJSON, "", `{"message":`,
+			http.StatusBadRequest, ""},
+		{"form", http.MethodPost, "/note/new", echo.MIMEApplicationForm, "", `message=hello&count=2`,
+			http.StatusCreated, ""},
+		{"invalid form", http.MethodPost, "/note/new", echo.MIMEApplicationForm, "", `count=two`,
+			http.StatusUnprocessableEntity,
+			`{"field":"count","rule":"type","param":"integer","message":"count must be of type integer"},` +
+				`{"field":"message","rule":"required","message":"message is required"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if len(tc.contentType) > 0 {
+				r.Header.Set(echo.HeaderContentType, tc.contentType)
+			}
+			if len(tc.header) > 0 {
+				r.Header.Set("X-Version", tc.header)
+			}
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.code, w.Code)
+			if len(tc.want) > 0 {
+				assert.Equal(t, `{"message":"the data submitted failed validation","status_code":422,`+
+					`"status_message":"Unprocessable Entity","errors":[`+tc.want+`]}`, strings.TrimSpace(w.Body.String()))
+			}
+		})
+	}
+}
+
+func TestSpecMaxBodySize(t *testing.T) {
+	e := specServer(t, swaggerSpec, octane.WithSpecMaxBodySize(16))
+
+	r := httptest.NewRequest(http.MethodPut, "/note/"+specNoteID, strings.NewReader(`{"message":"hello"}`))
+	r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, `{"message":"body must be at most 16 bytes","status_code":413,`+
+		`"status_message":"Request Entity Too Large"}`, strings.TrimSpace(w.Body.String()))
+
+	// A body within the limit is passed to the handler.
+	r = httptest.NewRequest(http.MethodPut, "/note/"+specNoteID, strings.NewReader(`{"message":"hi"}`))
+	r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSpecResponse(t *testing.T) {
+	e := specServer(t, swaggerSpec, octane.WithResponseValidation())
+
+	tests := []struct {
+		message string
+		code    int
+		want    string
+	}{
+		{"hello", http.StatusOK, `{"data":{"id":"hello"},"status_code":200}`},
+		{"empty", http.StatusNoContent, ``},
+		{"unchanged", http.StatusNotModified, ``},
+		{"missing", http.StatusInternalServerError, `{"message":"the response failed validation","status_code":500,` +
+			`"status_message":"Internal Server Error","errors":[` +
+			`{"field":"data","rule":"required","message":"data is required"},` +
+			`{"field":"status_code","rule":"type","param":"integer","message":"status_code must be of type integer"}]}`},
+		{"teapot", http.StatusInternalServerError, `{"message":"the response failed validation","status_code":500,` +
+			`"status_message":"Internal Server Error","errors":[` +
+			`{"field":"status_code","rule":"oneof","param":"200 204","message":"status_code must be one of [200 204]"}]}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.message, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/note/"+specNoteID,
+				strings.NewReader(`{"message":"`+tc.message+`"}`))
+			r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.code, w.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestSpecOpenAPI(t *testing.T) {
+	type request struct {
+		NoteID string `json:"note_id" in:"path"`
+		Limit  int    `json:"limit" in:"query" validate:"gt=0,lte=100"`
+		Body   struct {
+			Message string `json:"message" validate:"required,max=5"`
+		} `in:"body"`
+	}
+
+	api := octane.NewOpenAPI(octane.OpenAPIInfo{Title: "Notes", Version: "1.0.0"})
+	api.Describe(octane.Route{
+		Method:    http.MethodPut,
+		Path:      "/note/:note_id",
+		Request:   request{},
+		Responses: map[int]interface{}{http.StatusOK: octane.OKResponse{}},
+	})
+
+	doc, err := api.MarshalJSON()
+	assert.Nil(t, err)
+	e := specServer(t, string(doc))
+
+	r := httptest.NewRequest(http.MethodPut, "/note/10?limit=0", strings.NewReader(`{"message":"hello world"}`))
+	r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, `{"message":"the data submitted failed validation","status_code":422,`+
+		`"status_message":"Unprocessable Entity","errors":[`+
+		`{"field":"limit","rule":"gt","param":"0","message":"limit must be greater than 0"},`+
+		`{"field":"message","rule":"max","param":"5","message":"message must be at most 5 characters"}]}`,
+		strings.TrimSpace(w.Body.String()))
+}
@@ -0,0 +1,207 @@
+package octane
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationNDJSON is the media type of a newline delimited JSON
+// response.
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// streamEncoders are the formats of a StreamResponse in the order they are
+// used when the client accepts any media type.
+var streamEncoders = []responseEncoder{
+	{echo.MIMEApplicationJSON, nil, true, nil, newJSONStream},
+	{MIMEApplicationNDJSON, []string{"application/jsonl", "application/jsonlines"}, false, nil, newNDJSONStream},
+	{MIMETextCSV, nil, false, nil, newCSVStream},
+}
+
+// itemStream writes the items of a response as they are sent.
+type itemStream interface {
+	// item writes a single item.
+	item(v interface{}) error
+	// close writes the end of the response.
+	close() error
+}
+
+// StreamSend writes an item to the response of a StreamResponse.
+type StreamSend func(item interface{}) error
+
+// StreamResponse sends the items from fn to the response writer as they are
+// sent instead of holding the whole result set in memory. The format is
+// picked from the Accept header: JSON sends the items in the data array
+// with a status_code and a status_message, NDJSON sends each item on a
+// line, and CSV sends each item as a row with the header from the first
+// item. If none of the formats are acceptable, 406 is sent. Each item is
+// flushed to the client as soon as it's written.
+//
+// The status code is written with the first item. If fn returns an error
+// before an item is sent, the error is returned so it can be handled like
+// any other error. After that the response is already committed so the
+// response ends early and the error is only returned for logging. Send
+// returns an error when the client has gone away.
+func (c *ResponseJSON) StreamResponse(code int, fn func(send StreamSend) error) error {
+	res := c.Response()
+	res.Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+	mediaType, enc, ok := negotiate(c.Request().Header.Get(echo.HeaderAccept), streamEncoders)
+	if !ok {
+		return c.MessageResponse(notAcceptable(streamEncoders), http.StatusNotAcceptable)
+	}
+
+	w := bufio.NewWriter(res)
+	flush := func() error {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if f, ok := res.Writer.(http.Flusher); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	var stream itemStream
+	start := func() {
+		setContentType(res, mediaType)
+		res.WriteHeader(code)
+		stream = enc.stream(w, code)
+	}
+
+	ctx := c.Request().Context()
+	err := fn(func(item interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if stream == nil {
+			start()
+		}
+		if err := stream.item(item); err != nil {
+			return err
+		}
+		return flush()
+	})
+	if err != nil && stream == nil {
+		return err
+	}
+
+	if stream == nil {
+		start()
+	}
+	if err == nil {
+		err = stream.close()
+	}
+	if ferr := flush(); err == nil {
+		err = ferr
+	}
+
+	return err
+}
+
+// jsonStream writes the items in the data array of the envelope.
+type jsonStream struct {
+	w     io.Writer
+	code  int
+	count int
+}
+
+// newJSONStream returns a stream of the items in a JSON envelope.
+func newJSONStream(w io.Writer, code int) itemStream {
+	return &jsonStream{w: w, code: code}
+}
+
+func (s *jsonStream) item(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	prefix := ","
+	if s.count == 0 {
+		prefix = `{"data":[`
+	}
+	s.count++
+
+	if _, err = io.WriteString(s.w, prefix); err != nil {
+		return err
+	}
+	_, err = s.w.Write(b)
+	return err
+}
+
+func (s *jsonStream) close() error {
+	if s.count == 0 {
+		if _, err := io.WriteString(s.w, `{"data":[`); err != nil {
+			return err
+		}
+	}
+
+	status, err := json.Marshal(http.StatusText(s.code))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(s.w, `],"status_code":`+strconv.Itoa(s.code)+`,"status_message":`+string(status)+"}\n")
+	return err
+}
+
+// ndjsonStream writes each item as JSON on a line.
+type ndjsonStream struct {
+	enc *json.Encoder
+}
+
+// newNDJSONStream returns a stream of newline delimited JSON.
+func newNDJSONStream(w io.Writer, code int) itemStream {
+	return &ndjsonStream{enc: json.NewEncoder(w)}
+}
+
+func (s *ndjsonStream) item(v interface{}) error {
+	return s.enc.Encode(v)
+}
+
+func (s *ndjsonStream) close() error {
+	return nil
+}
+
+// csvStream writes each item as a row with the header from the first item.
+// The columns of a map are the keys of the first item.
+type csvStream struct {
+	w     *csv.Writer
+	table *csvTable
+}
+
+// newCSVStream returns a stream of CSV rows.
+func newCSVStream(w io.Writer, code int) itemStream {
+	return &csvStream{w: csv.NewWriter(w)}
+}
+
+func (s *csvStream) item(v interface{}) error {
+	row := reflect.ValueOf(v)
+	if s.table == nil {
+		rows := reflect.ValueOf([]interface{}{v})
+		table := newCSVTable(rows)
+		s.table = &table
+		if err := s.w.Write(table.header()); err != nil {
+			return err
+		}
+	}
+
+	if err := s.w.Write(s.table.record(row)); err != nil {
+		return err
+	}
+
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *csvStream) close() error {
+	s.w.Flush()
+	return s.w.Error()
+}
@@ -0,0 +1,130 @@
+package octane_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josephspurrier/octane"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// streamResponse streams the items with the Accept header.
+func streamResponse(accept string, fn func(send octane.StreamSend) error) (*httptest.ResponseRecorder, error) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if len(accept) > 0 {
+		req.Header.Set(echo.HeaderAccept, accept)
+	}
+	rec := httptest.NewRecorder()
+	c := &octane.ResponseJSON{Context: e.NewContext(req, rec)}
+	err := c.StreamResponse(http.StatusOK, fn)
+
+	return rec, err
+}
+
+// sendNotes sends two notes.
+func sendNotes(send octane.StreamSend) error {
+	notes := []negotiateNote{
+		{ID: "1", Message: "hello, world", Author: &negotiateAuthor{Email: "jsmith@example.com"}},
+		{ID: "2", Message: "no author"},
+	}
+	for _, note := range notes {
+		if err := send(note); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestStreamResponse(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		want        string
+	}{
+		{"", echo.MIMEApplicationJSON,
+			`{"data":[` +
+				`{"id":"1","message":"hello, world","count":0,"created":"0001-01-01T00:00:00Z","author":{"email":"jsmith@example.com"}},` +
+				`{"id":"2","message":"no author","count":0,"created":"0001-01-01T00:00:00Z","author":null}],` +
+				`"status_code":200,"status_message":"OK"}` + "\n"},
+		{octane.MIMEApplicationNDJSON, octane.MIMEApplicationNDJSON,
+			`{"id":"1","message":"hello, world","count":0,"created":"0001-01-01T00:00:00Z","author":{"email":"jsmith@example.com"}}` + "\n" +
+				`{"id":"2","message":"no author","count":0,"created":"0001-01-01T00:00:00Z","author":null}` + "\n"},
+		{"text/csv", "text/csv; charset=UTF-8",
+			"id,message,count,created,author.email\n" +
+				"1,\"hello, world\",0,0001-01-01T00:00:00Z,jsmith@example.com\n" +
+				"2,no author,0,0001-01-01T00:00:00Z,\n"},
+	}
+
+	for _, tc := range tests {
+		rec, err := streamResponse(tc.accept, sendNotes)
+		assert.Nil(t, err, tc.accept)
+		assert.Equal(t, http.StatusOK, rec.Code, tc.accept)
+		assert.Equal(t, tc.contentType, rec.Result().Header.Get(echo.HeaderContentType), tc.accept)
+		assert.True(t, rec.Flushed, tc.accept)
+		assert.Equal(t, tc.want, rec.Body.String(), tc.accept)
+	}
+}
+
+func TestStreamResponseEmpty(t *testing.T) {
+	rec, err := streamResponse("", func(send octane.StreamSend) error { return nil })
+	assert.Nil(t, err)
+	assert.Equal(t, `{"data":[],"status_code":200,"status_message":"OK"}`+"\n", rec.Body.String())
+}
+
+func TestStreamResponseError(t *testing.T) {
+	// The error is returned before anything is sent.
+	rec, err := streamResponse("", func(send octane.StreamSend) error {
+		return errors.New("query failed")
+	})
+	assert.EqualError(t, err, "query failed")
+	assert.False(t, rec.Flushed)
+	assert.Equal(t, 0, rec.Body.Len())
+
+	// The response ends early after the first item.
+	rec, err = streamResponse(octane.MIMEApplicationNDJSON, func(send octane.StreamSend) error {
+		if err := send(map[string]int{"id": 1}); err != nil {
+			return err
+		}
+		return errors.New("query failed")
+	})
+	assert.EqualError(t, err, "query failed")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"id":1}`+"\n", rec.Body.String())
+}
+
+func TestStreamResponseFlush(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAccept, octane.MIMEApplicationNDJSON)
+	rec := httptest.NewRecorder()
+	c := &octane.ResponseJSON{Context: e.NewContext(req, rec)}
+
+	// Each item is sent to the client before the next one.
+	err := c.StreamResponse(http.StatusOK, func(send octane.StreamSend) error {
+		for i, want := range []string{`{"id":1}` + "\n", `{"id":1}` + "\n" + `{"id":2}` + "\n"} {
+			if err := send(map[string]int{"id": i + 1}); err != nil {
+				return err
+			}
+			assert.True(t, rec.Flushed)
+			assert.Equal(t, want, rec.Body.String())
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestStreamResponseNotAcceptable(t *testing.T) {
+	rec, err := streamResponse(echo.MIMEApplicationXML, sendNotes)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+	assert.JSONEq(t, `{
+		"message": "the response is only available as application/json, application/x-ndjson, text/csv",
+		"status_code": 406,
+		"status_message": "Not Acceptable"
+	}`, rec.Body.String())
+}
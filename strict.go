@@ -0,0 +1,196 @@
+package octane
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	strictType      = reflect.TypeOf(Strict{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// Strict can be embedded in a request struct to reject the JSON keys and the
+// form keys that don't match a field even when the Binder is not strict.
+type Strict struct{}
+
+// strictBinding marks the struct as strict.
+func (Strict) strictBinding() {}
+
+// strictBinder is implemented by structs that embed Strict.
+type strictBinder interface {
+	strictBinding()
+}
+
+// isStrict returns true if unknown keys should be rejected.
+func (b *Binder) isStrict(iface interface{}) bool {
+	if b.strict {
+		return true
+	}
+
+	_, ok := iface.(strictBinder)
+	return ok
+}
+
+// unknownError returns a ValidationError with each unknown key or nil if
+// there are no unknown keys.
+func unknownError(keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	sort.Strings(keys)
+
+	ve := new(ValidationError)
+	for _, key := range keys {
+		ve.Errors = append(ve.Errors, FieldError{
+			Field:   key,
+			Rule:    "unknown",
+			Message: fmt.Sprintf("%s is not an allowed field", key),
+		})
+	}
+
+	return ve
+}
+
+// unknownJSON returns the keys of the JSON body that don't match a field of
+// the body or, if there is no body field, a form field. Nested objects and
+// arrays are checked as well.
+func (fields bindFields) unknownJSON(t reflect.Type, m map[string]interface{}) []string {
+	if body, ok := fields.body(); ok {
+		return unknownValue(m, t.Field(body.index).Type, "")
+	}
+
+	unknown := make([]string, 0)
+	for key, value := range m {
+		if f, ok := fields.findFold(key); ok && f.isForm() {
+			unknown = append(unknown, unknownValue(value, t.Field(f.index).Type, key)...)
+		} else {
+			unknown = append(unknown, key)
+		}
+	}
+
+	return unknown
+}
+
+// unknownForm returns the keys of the form that don't match a field of the
+// body or, if there is no body field, a form field.
+func (fields bindFields) unknownForm(t reflect.Type, form url.Values) []string {
+	body, hasBody := fields.body()
+
+	unknown := make([]string, 0)
+	for key := range form {
+		name := formKeyName(key)
+		if hasBody {
+			if _, ok := jsonField(t.Field(body.index).Type, name); ok {
+				continue
+			}
+		} else if f, ok := fields.find(name); ok && f.isForm() {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+
+	return unknown
+}
+
+// findFold returns the field with the matching name using the same case
+// insensitive matching as the JSON decoder.
+func (fields bindFields) findFold(name string) (bindField, bool) {
+	if f, ok := fields.find(name); ok {
+		return f, true
+	}
+
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+
+	return bindField{}, false
+}
+
+// unknownValue returns the keys of a JSON value that don't match the fields
+// of the type. Types with a custom unmarshaler and maps allow any key.
+func unknownValue(value interface{}, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	unknown := make([]string, 0)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+
+		for key, val := range v {
+			name := key
+			if len(path) > 0 {
+				name = path + "." + key
+			}
+
+			sf, ok := jsonField(t, key)
+			if !ok {
+				unknown = append(unknown, name)
+				continue
+			}
+			unknown = append(unknown, unknownValue(val, sf.Type, name)...)
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+
+		for i, val := range v {
+			unknown = append(unknown, unknownValue(val, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return unknown
+}
+
+// jsonField returns the field of a struct type that matches the JSON key.
+// The fields of embedded structs are also searched and the index of the field
+// includes the index of each embedded struct.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	for j := 0; j < t.NumField(); j++ {
+		sf := t.Field(j)
+		name := jsonName(sf)
+		if name == "-" || sf.Type == strictType {
+			continue
+		} else if sf.Anonymous && name == "" {
+			if f, ok := jsonField(sf.Type, key); ok {
+				f.Index = append([]int{j}, f.Index...)
+				return f, true
+			}
+			continue
+		} else if sf.PkgPath != "" {
+			continue
+		} else if name == "" {
+			name = sf.Name
+		}
+
+		if strings.EqualFold(name, key) {
+			return sf, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
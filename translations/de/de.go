@@ -0,0 +1,51 @@
+// Package de contains the German validation messages.
+package de
+
+import (
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/josephspurrier/octane/internal/translation"
+)
+
+// Messages contains the message for each validation rule.
+var Messages = map[string]string{
+	"required":   "{0} ist ein Pflichtfeld",
+	"email":      "{0} muss eine gültige E-Mail-Adresse sein",
+	"url":        "{0} muss eine gültige URL sein",
+	"uuid":       "{0} muss eine gültige UUID sein",
+	"uuid4":      "{0} muss eine gültige UUID der Version 4 sein",
+	"numeric":    "{0} muss ein gültiger numerischer Wert sein",
+	"alpha":      "{0} darf nur Buchstaben enthalten",
+	"alphanum":   "{0} darf nur Buchstaben und Ziffern enthalten",
+	"oneof":      "{0} muss einer der folgenden Werte sein: [{1}]",
+	"eq":         "{0} ist nicht gleich {1}",
+	"ne":         "{0} darf nicht gleich {1} sein",
+	"eqfield":    "{0} muss gleich {1} sein",
+	"nefield":    "{0} darf nicht gleich {1} sein",
+	"len-string": "{0} muss genau {1} Zeichen lang sein",
+	"len-items":  "{0} muss genau {1} Elemente enthalten",
+	"len-number": "{0} muss gleich {1} sein",
+	"min-string": "{0} muss mindestens {1} Zeichen lang sein",
+	"min-items":  "{0} muss mindestens {1} Elemente enthalten",
+	"min-number": "{0} muss {1} oder größer sein",
+	"max-string": "{0} darf höchstens {1} Zeichen lang sein",
+	"max-items":  "{0} darf höchstens {1} Elemente enthalten",
+	"max-number": "{0} muss {1} oder kleiner sein",
+	"gte-string": "{0} muss mindestens {1} Zeichen lang sein",
+	"gte-items":  "{0} muss mindestens {1} Elemente enthalten",
+	"gte-number": "{0} muss {1} oder größer sein",
+	"lte-string": "{0} darf höchstens {1} Zeichen lang sein",
+	"lte-items":  "{0} darf höchstens {1} Elemente enthalten",
+	"lte-number": "{0} muss {1} oder kleiner sein",
+	"gt-string":  "{0} muss länger als {1} Zeichen sein",
+	"gt-items":   "{0} muss mehr als {1} Elemente enthalten",
+	"gt-number":  "{0} muss größer als {1} sein",
+	"lt-string":  "{0} muss kürzer als {1} Zeichen sein",
+	"lt-items":   "{0} muss weniger als {1} Elemente enthalten",
+	"lt-number":  "{0} muss kleiner als {1} sein",
+}
+
+// RegisterDefaultTranslations registers the German validation messages.
+func RegisterDefaultTranslations(v *validator.Validate, trans ut.Translator) error {
+	return translation.Register(v, trans, Messages)
+}
@@ -0,0 +1,51 @@
+// Package es contains the Spanish validation messages.
+package es
+
+import (
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/josephspurrier/octane/internal/translation"
+)
+
+// Messages contains the message for each validation rule.
+var Messages = map[string]string{
+	"required":   "{0} es obligatorio",
+	"email":      "{0} debe ser una dirección de correo electrónico válida",
+	"url":        "{0} debe ser una URL válida",
+	"uuid":       "{0} debe ser un UUID válido",
+	"uuid4":      "{0} debe ser un UUID versión 4 válido",
+	"numeric":    "{0} debe ser un valor numérico válido",
+	"alpha":      "{0} solo puede contener caracteres alfabéticos",
+	"alphanum":   "{0} solo puede contener caracteres alfanuméricos",
+	"oneof":      "{0} debe ser uno de [{1}]",
+	"eq":         "{0} no es igual a {1}",
+	"ne":         "{0} no debe ser igual a {1}",
+	"eqfield":    "{0} debe ser igual a {1}",
+	"nefield":    "{0} no puede ser igual a {1}",
+	"len-string": "{0} debe tener {1} caracteres",
+	"len-items":  "{0} debe contener {1} elementos",
+	"len-number": "{0} debe ser igual a {1}",
+	"min-string": "{0} debe tener al menos {1} caracteres",
+	"min-items":  "{0} debe contener al menos {1} elementos",
+	"min-number": "{0} debe ser {1} o más",
+	"max-string": "{0} debe tener como máximo {1} caracteres",
+	"max-items":  "{0} debe contener como máximo {1} elementos",
+	"max-number": "{0} debe ser {1} o menos",
+	"gte-string": "{0} debe tener al menos {1} caracteres",
+	"gte-items":  "{0} debe contener al menos {1} elementos",
+	"gte-number": "{0} debe ser {1} o más",
+	"lte-string": "{0} debe tener como máximo {1} caracteres",
+	"lte-items":  "{0} debe contener como máximo {1} elementos",
+	"lte-number": "{0} debe ser {1} o menos",
+	"gt-string":  "{0} debe tener más de {1} caracteres",
+	"gt-items":   "{0} debe contener más de {1} elementos",
+	"gt-number":  "{0} debe ser mayor que {1}",
+	"lt-string":  "{0} debe tener menos de {1} caracteres",
+	"lt-items":   "{0} debe contener menos de {1} elementos",
+	"lt-number":  "{0} debe ser menor que {1}",
+}
+
+// RegisterDefaultTranslations registers the Spanish validation messages.
+func RegisterDefaultTranslations(v *validator.Validate, trans ut.Translator) error {
+	return translation.Register(v, trans, Messages)
+}
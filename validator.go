@@ -0,0 +1,83 @@
+package octane
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-playground/locales"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/josephspurrier/octane/internal/validation"
+)
+
+// Validator validates a struct after the request is bound. The languages
+// from the Accept-Language header are passed in order of preference, like
+// "de_AT" followed by "de", so the messages can be translated. The failed
+// fields are returned as a ValidationError with the path to each field using
+// the json tag names, like "Body.email". The Binder removes the name of the
+// body field from the path.
+type Validator interface {
+	ValidateStruct(ctx context.Context, s interface{}, langs []string) error
+}
+
+// playgroundValidator is the default Validator that uses
+// go-playground/validator v10.
+type playgroundValidator struct {
+	validate   *validator.Validate
+	translator *ut.UniversalTranslator
+}
+
+// newPlaygroundValidator returns a Validator with the validation messages
+// registered for each locale.
+func newPlaygroundValidator(v *validator.Validate, translations []translation) *playgroundValidator {
+	pv := &playgroundValidator{
+		validate: v,
+	}
+
+	if len(translations) == 0 {
+		return pv
+	}
+
+	arr := make([]locales.Translator, 0, len(translations))
+	for _, t := range translations {
+		arr = append(arr, t.locale)
+	}
+
+	pv.translator = ut.New(arr[0], arr...)
+	for _, t := range translations {
+		trans, _ := pv.translator.GetTranslator(t.locale.Locale())
+		if err := t.register(v, trans); err != nil {
+			panic(fmt.Sprintf("octane: could not register the %v translations: %v",
+				t.locale.Locale(), err))
+		}
+	}
+
+	return pv
+}
+
+// ValidateStruct will validate a struct and return the failed fields with
+// the messages for the first language that has translations.
+func (pv *playgroundValidator) ValidateStruct(ctx context.Context, s interface{}, langs []string) error {
+	err := pv.validate.StructCtx(ctx, s)
+	verrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	var trans ut.Translator
+	if pv.translator != nil {
+		trans, _ = pv.translator.FindTranslator(langs...)
+	}
+
+	ve := new(ValidationError)
+	for _, fe := range verrs {
+		ve.Errors = append(ve.Errors, FieldError{
+			Field:   validation.Field(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: validation.Message(fe, trans),
+		})
+	}
+
+	return ve
+}
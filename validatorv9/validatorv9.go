@@ -0,0 +1,109 @@
+// Package validatorv9 adapts gopkg.in/go-playground/validator.v9 to the
+// octane Validator interface for applications that still register rules
+// with the v9 validator.
+package validatorv9
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-playground/locales"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/josephspurrier/octane"
+	"github.com/josephspurrier/octane/internal/validation"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// Option configures a Validator.
+type Option func(*Validator)
+
+// TranslationFunc registers the validation messages for a translator. The
+// RegisterDefaultTranslations functions from the validator.v9 translations
+// packages can be used.
+type TranslationFunc func(v *validator.Validate, trans ut.Translator) error
+
+// translation contains a locale and its validation messages.
+type translation struct {
+	locale   locales.Translator
+	register TranslationFunc
+}
+
+// WithTranslation registers the validation messages for a locale. The first
+// locale registered is used when no locale matches the Accept-Language
+// header.
+func WithTranslation(locale locales.Translator, register TranslationFunc) Option {
+	return func(v *Validator) {
+		v.translations = append(v.translations, translation{
+			locale:   locale,
+			register: register,
+		})
+	}
+}
+
+// Validator validates the request structs using a v9 validator.
+type Validator struct {
+	validate     *validator.Validate
+	translator   *ut.UniversalTranslator
+	translations []translation
+}
+
+// New returns a Validator that uses the v9 validator. The json tag names are
+// registered with the validator so the field errors use the same names as
+// the request. Pass the Validator to octane.WithValidator.
+func New(v *validator.Validate, opts ...Option) *Validator {
+	v.RegisterTagNameFunc(validation.TagName)
+
+	vv := &Validator{
+		validate: v,
+	}
+
+	for _, opt := range opts {
+		opt(vv)
+	}
+
+	// Register the validation messages for each locale.
+	if len(vv.translations) > 0 {
+		arr := make([]locales.Translator, 0, len(vv.translations))
+		for _, t := range vv.translations {
+			arr = append(arr, t.locale)
+		}
+
+		vv.translator = ut.New(arr[0], arr...)
+		for _, t := range vv.translations {
+			trans, _ := vv.translator.GetTranslator(t.locale.Locale())
+			if err := t.register(v, trans); err != nil {
+				panic(fmt.Sprintf("validatorv9: could not register the %v translations: %v",
+					t.locale.Locale(), err))
+			}
+		}
+	}
+
+	return vv
+}
+
+// ValidateStruct will validate a struct and return the failed fields with
+// the messages for the first language that has translations.
+func (vv *Validator) ValidateStruct(ctx context.Context, s interface{}, langs []string) error {
+	err := vv.validate.StructCtx(ctx, s)
+	verrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	var trans ut.Translator
+	if vv.translator != nil {
+		trans, _ = vv.translator.FindTranslator(langs...)
+	}
+
+	ve := new(octane.ValidationError)
+	for _, fe := range verrs {
+		ve.Errors = append(ve.Errors, octane.FieldError{
+			Field:   validation.Field(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: validation.Message(fe.(validation.FieldError), trans),
+		})
+	}
+
+	return ve
+}
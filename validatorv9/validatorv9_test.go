@@ -0,0 +1,61 @@
+package validatorv9_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	localeen "github.com/go-playground/locales/en"
+	"github.com/josephspurrier/octane"
+	"github.com/josephspurrier/octane/validatorv9"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	validator "gopkg.in/go-playground/validator.v9"
+	"gopkg.in/go-playground/validator.v9/translations/en"
+)
+
+func TestValidator(t *testing.T) {
+	v := validator.New()
+	assert.NoError(t, v.RegisterValidation("even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}))
+
+	e := echo.New()
+	e.Binder = octane.NewBinder(octane.WithValidator(validatorv9.New(v,
+		validatorv9.WithTranslation(localeen.New(), en.RegisterDefaultTranslations),
+	)))
+
+	type request struct {
+		// in: body
+		Body struct {
+			Email string `json:"email" validate:"required,email"`
+			Count int    `json:"count" validate:"even"`
+		} `in:"body"`
+	}
+
+	var err error
+	e.POST("/user", func(c echo.Context) error {
+		err = c.Bind(new(request))
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/user", strings.NewReader(`{"email":"jsmith@example.com","count":2}`))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.NoError(t, err)
+
+	r = httptest.NewRequest("POST", "/user", strings.NewReader(`{"email":"jsmith","count":3}`))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, &octane.ValidationError{Errors: []octane.FieldError{{
+		Field:   "email",
+		Rule:    "email",
+		Message: "email must be a valid email address",
+	}, {
+		Field:   "count",
+		Rule:    "even",
+		Message: "count failed on the 'even' rule",
+	}}}, err)
+}